@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -14,6 +15,12 @@ type (
 		HTTP `yaml:"http"`
 		Log  `yaml:"logger"`
 		PG   `yaml:"postgres"`
+
+		Enrichment  `yaml:"enrichment"`
+		Names       `yaml:"names"`
+		Idempotency `yaml:"idempotency"`
+		Search      `yaml:"search"`
+		Purge       `yaml:"purge"`
 	}
 
 	// App -.
@@ -37,6 +44,91 @@ type (
 		PoolMax int    `env-required:"true" yaml:"pool_max" env:"PG_POOL_MAX"`
 		URL     string `env-required:"true"                 env:"PG_URL"`
 	}
+
+	// Names -.
+	Names struct {
+		Locale string `env-required:"true" yaml:"locale" env:"NAMES_LOCALE"`
+	}
+
+	// Idempotency -.
+	Idempotency struct {
+		TTL   time.Duration `env-required:"true" yaml:"ttl"   env:"IDEMPOTENCY_TTL"`
+		Lease time.Duration `env-required:"true" yaml:"lease" env:"IDEMPOTENCY_LEASE"`
+	}
+
+	// Purge -.
+	Purge struct {
+		Interval time.Duration `env-required:"true" yaml:"interval" env:"PURGE_INTERVAL"`
+	}
+
+	// Search -.
+	Search struct {
+		MaxPerPage int `env-required:"true" yaml:"max_per_page" env:"SEARCH_MAX_PER_PAGE"`
+	}
+
+	// Enrichment -.
+	Enrichment struct {
+		APIKey      string        `yaml:"api_key"                      env:"ENRICHMENT_API_KEY"`
+		Timeout     time.Duration `env-required:"true" yaml:"timeout" env:"ENRICHMENT_TIMEOUT"`
+		Agify       Provider      `yaml:"agify"       env-prefix:"ENRICHMENT_AGIFY_"`
+		Genderize   Provider      `yaml:"genderize"   env-prefix:"ENRICHMENT_GENDERIZE_"`
+		Nationalize Provider      `yaml:"nationalize" env-prefix:"ENRICHMENT_NATIONALIZE_"`
+		TLS         TLS           `yaml:"tls"`
+		Cache       Cache         `yaml:"cache"`
+		Thresholds  Thresholds    `yaml:"thresholds"`
+
+		Retry          Retry          `yaml:"retry"`
+		CircuitBreaker CircuitBreaker `yaml:"circuit_breaker"`
+		Async          Async          `yaml:"async"`
+	}
+
+	// Provider -.
+	Provider struct {
+		URL     string        `env-required:"true" yaml:"url"     env:"URL"`
+		Timeout time.Duration `env-required:"true" yaml:"timeout" env:"TIMEOUT"`
+	}
+
+	// Cache -.
+	Cache struct {
+		Size int           `env-required:"true" yaml:"size" env:"ENRICHMENT_CACHE_SIZE"`
+		TTL  time.Duration `env-required:"true" yaml:"ttl"  env:"ENRICHMENT_CACHE_TTL"`
+	}
+
+	// Thresholds -.
+	Thresholds struct {
+		AgeMinCount               int     `yaml:"age_min_count"               env:"ENRICHMENT_THRESHOLDS_AGE_MIN_COUNT"`
+		GenderMinProbability      float64 `yaml:"gender_min_probability"      env:"ENRICHMENT_THRESHOLDS_GENDER_MIN_PROBABILITY"`
+		NationalityMinProbability float64 `yaml:"nationality_min_probability" env:"ENRICHMENT_THRESHOLDS_NATIONALITY_MIN_PROBABILITY"`
+	}
+
+	// Retry -.
+	Retry struct {
+		MaxAttempts int           `env-required:"true" yaml:"max_attempts" env:"ENRICHMENT_RETRY_MAX_ATTEMPTS"`
+		MinBackoff  time.Duration `env-required:"true" yaml:"min_backoff"  env:"ENRICHMENT_RETRY_MIN_BACKOFF"`
+		MaxBackoff  time.Duration `env-required:"true" yaml:"max_backoff"  env:"ENRICHMENT_RETRY_MAX_BACKOFF"`
+	}
+
+	// CircuitBreaker -.
+	CircuitBreaker struct {
+		FailureThreshold int           `env-required:"true" yaml:"failure_threshold" env:"ENRICHMENT_CIRCUIT_BREAKER_FAILURE_THRESHOLD"`
+		Cooldown         time.Duration `env-required:"true" yaml:"cooldown"          env:"ENRICHMENT_CIRCUIT_BREAKER_COOLDOWN"`
+	}
+
+	// Async -.
+	Async struct {
+		Enabled       bool          `yaml:"enabled"                            env:"ENRICHMENT_ASYNC_ENABLED"`
+		Workers       int           `env-required:"true" yaml:"workers"        env:"ENRICHMENT_ASYNC_WORKERS"`
+		BatchSize     int           `env-required:"true" yaml:"batch_size"     env:"ENRICHMENT_ASYNC_BATCH_SIZE"`
+		PollInterval  time.Duration `env-required:"true" yaml:"poll_interval"  env:"ENRICHMENT_ASYNC_POLL_INTERVAL"`
+		RetryInterval time.Duration `env-required:"true" yaml:"retry_interval" env:"ENRICHMENT_ASYNC_RETRY_INTERVAL"`
+		MaxAttempts   int           `env-required:"true" yaml:"max_attempts"   env:"ENRICHMENT_ASYNC_MAX_ATTEMPTS"`
+	}
+
+	// TLS -.
+	TLS struct {
+		CAFile             string `yaml:"ca_file"              env:"ENRICHMENT_TLS_CA_FILE"`
+		InsecureSkipVerify bool   `yaml:"insecure_skip_verify" env:"ENRICHMENT_TLS_INSECURE_SKIP_VERIFY"`
+	}
 )
 
 func NewConfig() (*Config, error) {
@@ -49,5 +141,10 @@ func NewConfig() (*Config, error) {
 		return nil, fmt.Errorf("config error: %w", err)
 	}
 
+	// Searches divide by the page size, so a page must hold at least one person.
+	if cfg.Search.MaxPerPage < 1 {
+		return nil, fmt.Errorf("config error: search.max_per_page must be at least 1, got %d", cfg.Search.MaxPerPage)
+	}
+
 	return cfg, nil
 }
@@ -18,11 +18,33 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/enrichment/cache": {
+            "get": {
+                "description": "Returns how many enrichments were served from memory, from the database and from the providers",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enrichment"
+                ],
+                "summary": "Enrichment cache statistics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.CacheStats"
+                        }
+                    }
+                }
+            }
+        },
         "/people": {
             "get": {
                 "description": "Returns a list of people matching the specified search criteria",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "People",
                     "People"
                 ],
                 "summary": "Search people",
@@ -45,6 +67,18 @@ const docTemplate = `{
                         "name": "patronymic",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "description": "Name starts with",
+                        "name": "name_prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Surname contains, ignoring case",
+                        "name": "surname_ilike",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "description": "Age",
@@ -52,68 +86,279 @@ const docTemplate = `{
                         "in": "query"
                     },
                     {
+                        "type": "integer",
+                        "description": "Age is at least",
+                        "name": "age_gte",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Age is at most",
+                        "name": "age_lte",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "male",
+                            "female"
+                        ],
                         "type": "string",
                         "description": "Gender",
                         "name": "gender",
                         "in": "query"
                     },
                     {
+                        "enum": [
+                            "male",
+                            "female"
+                        ],
                         "type": "string",
-                        "description": "Nationality",
+                        "description": "Gender is not, as in gender!=male",
+                        "name": "gender!",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated nationalities, any of which matches",
                         "name": "nationality",
                         "in": "query"
                     },
                     {
+                        "type": "string",
+                        "default": "id",
+                        "description": "Comma-separated fields to sort by, prefixed with - for descending: id, name, surname, patronymic, age, gender, nationality",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
                         "type": "integer",
+                        "default": 1,
                         "description": "Page",
                         "name": "page",
                         "in": "query"
                     },
                     {
+                        "minimum": 1,
                         "type": "integer",
-                        "description": "Persons per page",
+                        "default": 10,
+                        "description": "Persons per page, up to search.max_per_page",
                         "name": "perPage",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page; the filters and sort must stay the same",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Persons per page after the cursor, up to search.max_per_page",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.PeoplePage"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Links to the next and previous pages"
+                            }
+                        }
                     },
-                    "400": {
-                        "description": "Bad Request"
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
                     }
                 }
             },
             "post": {
-                "description": "Get name, surname, patronymic, enrich with age, gender and nationality, and save to database",
+                "description": "Get name, surname, patronymic, enrich with age, gender and nationality, and save to database.\nIn async enrichment mode the person is stored as pending and enriched in the background.",
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "People"
                 ],
                 "summary": "Create person",
+                "parameters": [
+                    {
+                        "description": "Person",
+                        "name": "person",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.PersonInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes the request safe to retry; repeats get the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
                 "responses": {
                     "201": {
-                        "description": "Created"
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entity.EnrichedPerson"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the person"
+                            },
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the created person"
+                            }
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/entity.EnrichedPerson"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the person"
+                            },
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the created person"
+                            }
+                        }
                     },
                     "400": {
-                        "description": "Bad Request"
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the already stored person"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
                     }
                 }
             }
         },
         "/people/{id}": {
+            "get": {
+                "description": "Get person by id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "People"
+                ],
+                "summary": "Get person",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.EnrichedPerson"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the person"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    }
+                }
+            },
             "put": {
-                "description": "Update person by id",
+                "description": "Replace person by id. Attributes left out of the body are cleared; the person isn't re-enriched.",
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "People"
                 ],
-                "summary": "Update person",
+                "summary": "Replace person",
                 "parameters": [
                     {
                         "type": "integer",
@@ -121,14 +366,89 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Person",
+                        "name": "person",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.EnrichedPerson"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes the request safe to retry; repeats get the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the person must still have",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.EnrichedPerson"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the person"
+                            }
+                        }
                     },
                     "400": {
-                        "description": "Bad Request"
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
                     }
                 }
             },
@@ -145,6 +465,12 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the person must still have",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -152,8 +478,354 @@ const docTemplate = `{
                         "description": "OK"
                     },
                     "400": {
-                        "description": "Bad Request"
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Change some fields of a person by id with a JSON Merge Patch (RFC 7396); null clears a field.\nChanging the name or the country hint re-enriches the person, keeping the attributes set by the patch.",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "People"
+                ],
+                "summary": "Patch person",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Merge patch of name, surname, patronymic, age, gender, nationality, country_hint and locale",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.EnrichedPerson"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes the request safe to retry; repeats get the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the person must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.EnrichedPerson"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the person"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "entity.CacheStats": {
+            "type": "object",
+            "properties": {
+                "database_hits": {
+                    "type": "integer"
+                },
+                "memory_hits": {
+                    "type": "integer"
+                },
+                "misses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.EnrichedPerson": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "description": "Age, Gender and Nationality are nil when they are unknown or the\nprovider wasn't confident enough about them.",
+                    "type": "integer",
+                    "maximum": 150,
+                    "minimum": 0
+                },
+                "age_count": {
+                    "type": "integer"
+                },
+                "country_hint": {
+                    "type": "string"
+                },
+                "enrichment_status": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string",
+                    "enum": [
+                        "male",
+                        "female"
+                    ]
+                },
+                "gender_probability": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "locale": {
+                    "description": "Locale is only read from requests to capitalize the names; it isn't stored.",
+                    "type": "string"
+                },
+                "low_confidence": {
+                    "description": "LowConfidence lists the attributes left unknown because the provider's\nconfidence was below the configured threshold.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "nationalities": {
+                    "description": "Nationalities are ranked from the most to the least likely.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.NationalityProbability"
+                    }
+                },
+                "nationality": {
+                    "type": "string"
+                },
+                "patronymic": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "surname": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "entity.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.NationalityProbability": {
+            "type": "object",
+            "properties": {
+                "country_id": {
+                    "type": "string"
+                },
+                "probability": {
+                    "type": "number"
+                }
+            }
+        },
+        "entity.PageLinks": {
+            "type": "object",
+            "properties": {
+                "next": {
+                    "type": "string"
+                },
+                "prev": {
+                    "type": "string"
+                },
+                "self": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.PeoplePage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.EnrichedPerson"
+                    }
+                },
+                "links": {
+                    "$ref": "#/definitions/entity.PageLinks"
+                },
+                "next_cursor": {
+                    "description": "NextCursor is empty on the last page.",
+                    "type": "string"
+                },
+                "page": {
+                    "description": "Page is only set for numbered pages, cursor pages have no number.",
+                    "type": "integer"
+                },
+                "per_page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "Total is the number of people matching the search on all the pages.",
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.PersonInput": {
+            "type": "object",
+            "properties": {
+                "country_hint": {
+                    "description": "CountryHint is an optional ISO 3166-1 alpha-2 code used to localize the age and gender predictions.",
+                    "type": "string"
+                },
+                "locale": {
+                    "description": "Locale is a BCP 47 language tag used to capitalize the names. It defaults to the Accept-Language header.",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "patronymic": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "surname": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "v1.ErrResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "machine-readable error code",
+                    "type": "string"
+                },
+                "detail": {
+                    "description": "explanation specific to this occurrence",
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "invalid fields of the request",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.FieldError"
                     }
+                },
+                "existing_id": {
+                    "description": "person the request conflicts with",
+                    "type": "integer"
+                },
+                "instance": {
+                    "description": "URI of the request that caused the problem",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "http response status code",
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "short summary of the problem type",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "URI identifying the problem type",
+                    "type": "string"
                 }
             }
         }
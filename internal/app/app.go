@@ -12,10 +12,14 @@ import (
 
 	"github.com/realPointer/EnrichInfo/config"
 	v1 "github.com/realPointer/EnrichInfo/internal/controller/http/v1"
+	"github.com/realPointer/EnrichInfo/internal/enricher"
+	"github.com/realPointer/EnrichInfo/internal/enricher/webapi"
 	"github.com/realPointer/EnrichInfo/internal/repo"
 	"github.com/realPointer/EnrichInfo/internal/service"
+	"github.com/realPointer/EnrichInfo/pkg/httpclient"
 	"github.com/realPointer/EnrichInfo/pkg/httpserver"
 	"github.com/realPointer/EnrichInfo/pkg/logger"
+	"github.com/realPointer/EnrichInfo/pkg/namecase"
 	"github.com/realPointer/EnrichInfo/pkg/postgres"
 )
 
@@ -47,17 +51,73 @@ func Run() {
 	l.Info("Initializing repositories...")
 	repositories := repo.NewRepositories(pg)
 
+	// Enrichers
+	l.Info("Initializing enrichers...")
+	tlsConfig, err := httpclient.NewTLSConfig(cfg.Enrichment.TLS.CAFile, cfg.Enrichment.TLS.InsecureSkipVerify)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - httpclient.NewTLSConfig: %w", err))
+	}
+
+	newEnrichmentClient := func(provider config.Provider) *httpclient.Client {
+		return httpclient.New(
+			httpclient.Timeout(provider.Timeout),
+			httpclient.TLSConfig(tlsConfig),
+			httpclient.MaxAttempts(cfg.Enrichment.Retry.MaxAttempts),
+			httpclient.Backoff(cfg.Enrichment.Retry.MinBackoff, cfg.Enrichment.Retry.MaxBackoff),
+			httpclient.CircuitBreaker(cfg.Enrichment.CircuitBreaker.FailureThreshold, cfg.Enrichment.CircuitBreaker.Cooldown),
+		)
+	}
+
+	enrichers := enricher.NewEnrichers(
+		webapi.NewAgify(cfg.Enrichment.Agify.URL, cfg.Enrichment.APIKey, newEnrichmentClient(cfg.Enrichment.Agify)),
+		webapi.NewGenderize(cfg.Enrichment.Genderize.URL, cfg.Enrichment.APIKey, newEnrichmentClient(cfg.Enrichment.Genderize)),
+		webapi.NewNationalize(cfg.Enrichment.Nationalize.URL, cfg.Enrichment.APIKey, newEnrichmentClient(cfg.Enrichment.Nationalize)),
+		l,
+		enricher.Timeout(cfg.Enrichment.Timeout),
+	)
+	cachedEnricher := enricher.NewCachedEnricher(
+		enrichers,
+		repositories.EnrichmentCache,
+		cfg.Enrichment.Cache.Size,
+		cfg.Enrichment.Cache.TTL,
+		l,
+	)
+	confidenceEnricher := enricher.NewConfidenceEnricher(cachedEnricher, enricher.Thresholds{
+		AgeMinCount:               cfg.Enrichment.Thresholds.AgeMinCount,
+		GenderMinProbability:      cfg.Enrichment.Thresholds.GenderMinProbability,
+		NationalityMinProbability: cfg.Enrichment.Thresholds.NationalityMinProbability,
+	})
+
 	// Services dependencies
 	l.Info("Initializing services...")
 	deps := service.ServicesDependencies{
-		Repos: repositories,
+		Repos:            repositories,
+		Enricher:         confidenceEnricher,
+		EnrichmentCache:  cachedEnricher,
+		Names:            namecase.New(namecase.Locale(cfg.Names.Locale)),
+		AsyncEnrichment:  cfg.Enrichment.Async.Enabled,
+		IdempotencyTTL:   cfg.Idempotency.TTL,
+		IdempotencyLease: cfg.Idempotency.Lease,
 	}
 	services := service.NewServices(deps)
 
+	// Background enrichment
+	if cfg.Enrichment.Async.Enabled {
+		l.Info("Starting enrichment workers...")
+		worker := newEnrichmentWorker(repositories.Person, confidenceEnricher, cfg.Enrichment.Async, l)
+		worker.Start()
+		defer worker.Stop()
+	}
+
+	// Expired rows are purged in the background
+	purger := newPurger(repositories.Idempotency, repositories.EnrichmentCache, cfg.Purge.Interval, l)
+	purger.Start()
+	defer purger.Stop()
+
 	// HTTP Server
 	l.Info("Initializing handlers and routes...")
 	handler := chi.NewRouter()
-	v1.NewRouter(handler, l, services)
+	v1.NewRouter(handler, l, services, cfg.Search.MaxPerPage)
 	httpServer := httpserver.New(handler, httpserver.Port(cfg.HTTP.Port))
 
 	// Waiting signal
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/realPointer/EnrichInfo/internal/repo"
+	"github.com/realPointer/EnrichInfo/pkg/logger"
+)
+
+// purger periodically deletes the expired idempotency keys and enrichment
+// cache entries, which are only skipped when they are read.
+type purger struct {
+	idempotencyRepo     repo.Idempotency
+	enrichmentCacheRepo repo.EnrichmentCache
+	interval            time.Duration
+	l                   logger.Interface
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newPurger(idempotencyRepo repo.Idempotency, enrichmentCacheRepo repo.EnrichmentCache, interval time.Duration, l logger.Interface) *purger {
+	return &purger{
+		idempotencyRepo:     idempotencyRepo,
+		enrichmentCacheRepo: enrichmentCacheRepo,
+		interval:            interval,
+		l:                   l,
+		done:                make(chan struct{}),
+	}
+}
+
+func (p *purger) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	go func() {
+		defer close(p.done)
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			p.purge(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop waits for the purge in progress to be cancelled.
+func (p *purger) Stop() {
+	if p.cancel == nil {
+		return
+	}
+
+	p.cancel()
+	<-p.done
+}
+
+func (p *purger) purge(ctx context.Context) {
+	keys, err := p.idempotencyRepo.DeleteExpiredIdempotencyKeys(ctx)
+	if err != nil && ctx.Err() == nil {
+		p.l.Error("purger - purge - p.idempotencyRepo.DeleteExpiredIdempotencyKeys: %v", err)
+	}
+
+	enrichments, err := p.enrichmentCacheRepo.DeleteExpiredEnrichments(ctx)
+	if err != nil && ctx.Err() == nil {
+		p.l.Error("purger - purge - p.enrichmentCacheRepo.DeleteExpiredEnrichments: %v", err)
+	}
+
+	p.l.Debug("Purged %d idempotency keys and %d enrichment cache entries", keys, enrichments)
+}
@@ -0,0 +1,167 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/realPointer/EnrichInfo/config"
+	"github.com/realPointer/EnrichInfo/internal/enricher"
+	"github.com/realPointer/EnrichInfo/internal/entity"
+	"github.com/realPointer/EnrichInfo/internal/repo"
+	"github.com/realPointer/EnrichInfo/pkg/logger"
+)
+
+// enrichmentWorker drains people stored as pending in async enrichment mode.
+// A dispatcher claims batches of pending people and hands them to a pool of
+// workers; failed enrichments are retried after the retry interval.
+type enrichmentWorker struct {
+	personRepo repo.Person
+	enricher   enricher.Enricher
+	cfg        config.Async
+	l          logger.Interface
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newEnrichmentWorker(personRepo repo.Person, enricher enricher.Enricher, cfg config.Async, l logger.Interface) *enrichmentWorker {
+	return &enrichmentWorker{
+		personRepo: personRepo,
+		enricher:   enricher,
+		cfg:        cfg,
+		l:          l,
+		done:       make(chan struct{}),
+	}
+}
+
+func (w *enrichmentWorker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	jobs := make(chan *entity.EnrichedPerson)
+
+	var wg sync.WaitGroup
+	for i := 0; i < w.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for person := range jobs {
+				w.process(ctx, person)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(w.done)
+		defer wg.Wait()
+		defer close(jobs)
+
+		ticker := time.NewTicker(w.cfg.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			w.dispatch(ctx, jobs)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop cancels in-flight enrichments and waits for the workers to exit.
+// Their leases expire after the retry interval, so those people are picked up again.
+func (w *enrichmentWorker) Stop() {
+	if w.cancel == nil {
+		return
+	}
+
+	w.cancel()
+	<-w.done
+}
+
+// dispatch hands out pending people until there are none left.
+func (w *enrichmentWorker) dispatch(ctx context.Context, jobs chan<- *entity.EnrichedPerson) {
+	for {
+		// A claimed person is leased for the retry interval, so it's picked up
+		// again if this instance dies before finishing it.
+		people, err := w.personRepo.ClaimPendingPeople(ctx, w.cfg.BatchSize, w.cfg.MaxAttempts, time.Now().Add(w.cfg.RetryInterval))
+		if err != nil {
+			if ctx.Err() == nil {
+				w.l.Error("enrichmentWorker - dispatch - w.personRepo.ClaimPendingPeople: %v", err)
+			}
+			return
+		}
+
+		for _, person := range people {
+			select {
+			case jobs <- person:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if len(people) < w.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+func (w *enrichmentWorker) process(ctx context.Context, person *entity.EnrichedPerson) {
+	enrichedPerson, err := w.enricher.Enrich(ctx, &entity.PersonInput{
+		Name:        person.Name,
+		Surname:     person.Surname,
+		Patronymic:  person.Patronymic,
+		CountryHint: person.CountryHint,
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+
+		w.l.Warn("enrichmentWorker - process - w.enricher.Enrich: person %d: %v", person.ID, err)
+
+		err = w.personRepo.FailEnrichment(ctx, person.ID, time.Now().Add(w.cfg.RetryInterval))
+		if err != nil {
+			w.l.Error("enrichmentWorker - process - w.personRepo.FailEnrichment: %v", err)
+		}
+		return
+	}
+
+	// Only fill the attributes that are still unknown, the others were set by
+	// a client while the person was pending.
+	enrichment := enrichedPerson.Enrichment()
+	if person.Age != nil {
+		enrichment.Age = person.Age
+		enrichedPerson.SetByClient(entity.AttributeAge)
+	}
+	if person.Gender != nil {
+		enrichment.Gender = person.Gender
+		enrichedPerson.SetByClient(entity.AttributeGender)
+	}
+	if person.Nationality != nil {
+		enrichment.Nationality = person.Nationality
+		enrichedPerson.SetByClient(entity.AttributeNationality)
+	}
+	enrichedPerson.SetEnrichment(enrichment)
+
+	enrichedPerson.EnrichmentStatus = entity.EnrichmentDone
+	enrichedPerson.Version = person.Version
+
+	err = w.personRepo.UpdatePerson(ctx, person.ID, enrichedPerson)
+	if errors.Is(err, entity.ErrPersonModified) || errors.Is(err, entity.ErrPersonNotFound) {
+		// The person was changed or deleted by a client meanwhile, the client wins.
+		w.l.Debug("Person %d changed during background enrichment: %v", person.ID, err)
+		return
+	}
+	if err != nil {
+		w.l.Error("enrichmentWorker - process - w.personRepo.UpdatePerson: %v", err)
+		return
+	}
+
+	w.l.Debug("Person %d enriched in background", person.ID)
+}
@@ -0,0 +1,37 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/realPointer/EnrichInfo/internal/service"
+	"github.com/realPointer/EnrichInfo/pkg/logger"
+)
+
+type enrichmentRoutes struct {
+	enrichmentService service.Enrichment
+	l                 logger.Interface
+}
+
+func NewEnrichmentRouter(enrichmentService service.Enrichment, l logger.Interface) http.Handler {
+	e := enrichmentRoutes{
+		enrichmentService: enrichmentService,
+		l:                 l,
+	}
+	r := chi.NewRouter()
+
+	r.Get("/cache", e.getCacheStats)
+
+	return r
+}
+
+// @Summary Enrichment cache statistics
+// @Description Returns how many enrichments were served from memory, from the database and from the providers
+// @Tags Enrichment
+// @Produce json
+// @Success 200 {object} entity.CacheStats
+// @Router /enrichment/cache [get]
+func (e *enrichmentRoutes) getCacheStats(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, e.enrichmentService.CacheStats())
+}
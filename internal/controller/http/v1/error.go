@@ -1,34 +1,190 @@
 package v1
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/go-chi/render"
+	"github.com/realPointer/EnrichInfo/internal/entity"
+	"github.com/realPointer/EnrichInfo/pkg/logger"
 )
 
+// Machine-readable error codes. Clients may rely on them, so never change existing ones.
+const (
+	CodeBadRequest           = "bad_request"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeNotFound             = "not_found"
+	CodeConflict             = "conflict"
+	CodePreconditionFailed   = "precondition_failed"
+	CodeValidationFailed     = "validation_failed"
+	CodeUpstreamUnavailable  = "upstream_unavailable"
+	CodeProviderUnavailable  = "provider_unavailable"
+	CodeIdempotencyKeyInUse  = "idempotency_key_in_use"
+	CodeIdempotencyKeyReused = "idempotency_key_reused"
+	CodeInternal             = "internal_error"
+)
+
+const (
+	_problemContentType = "application/problem+json"
+	_problemTypePrefix  = "urn:enrichinfo:problem:"
+)
+
+// ErrResponse is an RFC 7807 problem details document.
 type ErrResponse struct {
-	HTTPStatusCode int    `json:"-"`               // http response status code
-	StatusText     string `json:"status"`          // user-level status message
-	ErrorText      string `json:"error,omitempty"` // application-level error message, for debugging
+	Type           string              `json:"type"`                  // URI identifying the problem type
+	Title          string              `json:"title"`                 // short summary of the problem type
+	HTTPStatusCode int                 `json:"status"`                // http response status code
+	Detail         string              `json:"detail,omitempty"`      // explanation specific to this occurrence
+	Instance       string              `json:"instance,omitempty"`    // URI of the request that caused the problem
+	Code           string              `json:"code"`                  // machine-readable error code
+	Errors         []entity.FieldError `json:"errors,omitempty"`      // invalid fields of the request
+	ExistingID     int                 `json:"existing_id,omitempty"` // person the request conflicts with
 }
 
 func (e *ErrResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	e.Instance = r.URL.RequestURI()
 	render.Status(r, e.HTTPStatusCode)
 	return nil
 }
 
-func ErrorInvalidRequest(err error) render.Renderer {
-	return &ErrResponse{
-		HTTPStatusCode: http.StatusBadRequest,
-		StatusText:     "Bad Request",
-		ErrorText:      err.Error(),
+// Respond is a render.Respond replacement that writes problem details as
+// application/problem+json and everything else with render.DefaultResponder.
+func Respond(w http.ResponseWriter, r *http.Request, v interface{}) {
+	problem, ok := v.(*ErrResponse)
+	if !ok {
+		render.DefaultResponder(w, r, v)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	if err := json.NewEncoder(buf).Encode(problem); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", _problemContentType)
+	w.WriteHeader(problem.HTTPStatusCode)
+	w.Write(buf.Bytes()) //nolint:errcheck // nothing left to do if the client is gone
+}
+
+func newProblem(status int, code string, err error) *ErrResponse {
+	problem := &ErrResponse{
+		Type:           _problemTypePrefix + code,
+		Title:          http.StatusText(status),
+		HTTPStatusCode: status,
+		Code:           code,
+	}
+	if err != nil {
+		problem.Detail = err.Error()
 	}
+
+	return problem
+}
+
+// ErrorResponse translates an error returned by the services into the
+// matching response. Errors of unknown kind are treated as internal.
+func ErrorResponse(err error) render.Renderer {
+	switch {
+	case errors.Is(err, entity.ErrIdempotencyKeyInUse):
+		return newProblem(http.StatusConflict, CodeIdempotencyKeyInUse, err)
+	case errors.Is(err, entity.ErrIdempotencyKeyReused):
+		return newProblem(http.StatusUnprocessableEntity, CodeIdempotencyKeyReused, err)
+	case errors.Is(err, entity.ErrNotFound):
+		return ErrorNotFound(err)
+	case errors.Is(err, entity.ErrConflict):
+		return ErrorConflict(err)
+	case errors.Is(err, entity.ErrPreconditionFailed):
+		return ErrorPreconditionFailed(err)
+	case errors.Is(err, entity.ErrValidation):
+		return ErrorValidation(err)
+	case errors.Is(err, entity.ErrProviderUnavailable):
+		return ErrorServiceUnavailable(err)
+	case errors.Is(err, entity.ErrUpstreamUnavailable):
+		return ErrorBadGateway(err)
+	default:
+		return ErrorInternal()
+	}
+}
+
+// renderError responds with the problem ErrorResponse maps err to. A 500
+// doesn't tell the client what went wrong, so its cause is logged as an error;
+// the other problems are only logged for debugging.
+func renderError(w http.ResponseWriter, r *http.Request, l logger.Interface, err error, format string, args ...interface{}) {
+	problem := ErrorResponse(err)
+
+	message := fmt.Sprintf(format, args...)
+	if p, ok := problem.(*ErrResponse); ok && p.HTTPStatusCode == http.StatusInternalServerError {
+		l.Error("%s %s: %s: %v", r.Method, r.URL.RequestURI(), message, err)
+	} else {
+		l.Debug("%s: %v", message, err)
+	}
+
+	render.Render(w, r, problem)
+}
+
+// ErrorBind responds to a failed render.Bind: 422 if the payload was decoded
+// but is invalid, 400 if it couldn't be decoded at all.
+func ErrorBind(err error) render.Renderer {
+	if errors.Is(err, entity.ErrValidation) {
+		return ErrorValidation(err)
+	}
+
+	return ErrorInvalidRequest(err)
+}
+
+func ErrorInvalidRequest(err error) render.Renderer {
+	return newProblem(http.StatusBadRequest, CodeBadRequest, err)
+}
+
+func ErrorUnsupportedMediaType(err error) render.Renderer {
+	return newProblem(http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, err)
 }
 
 func ErrorNotFound(err error) render.Renderer {
-	return &ErrResponse{
-		HTTPStatusCode: http.StatusNotFound,
-		StatusText:     "Not Found",
-		ErrorText:      err.Error(),
+	return newProblem(http.StatusNotFound, CodeNotFound, err)
+}
+
+// ErrorConflict points to the already stored person in the existing_id member of the problem.
+func ErrorConflict(err error) render.Renderer {
+	problem := newProblem(http.StatusConflict, CodeConflict, err)
+
+	var dup *entity.DuplicatePersonError
+	if errors.As(err, &dup) {
+		problem.ExistingID = dup.ExistingID
 	}
+
+	return problem
+}
+
+func ErrorPreconditionFailed(err error) render.Renderer {
+	return newProblem(http.StatusPreconditionFailed, CodePreconditionFailed, err)
+}
+
+// ErrorValidation lists every invalid field in the errors member of the problem.
+func ErrorValidation(err error) render.Renderer {
+	problem := newProblem(http.StatusUnprocessableEntity, CodeValidationFailed, err)
+
+	var verr *entity.ValidationError
+	if errors.As(err, &verr) {
+		problem.Detail = "The request has invalid fields."
+		problem.Errors = verr.Fields
+	}
+
+	return problem
+}
+
+func ErrorBadGateway(err error) render.Renderer {
+	return newProblem(http.StatusBadGateway, CodeUpstreamUnavailable, err)
+}
+
+func ErrorServiceUnavailable(err error) render.Renderer {
+	return newProblem(http.StatusServiceUnavailable, CodeProviderUnavailable, err)
+}
+
+// ErrorInternal doesn't expose the error to the client, it has to be logged instead.
+func ErrorInternal() render.Renderer {
+	return newProblem(http.StatusInternalServerError, CodeInternal, nil)
 }
@@ -0,0 +1,147 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/render"
+	"github.com/realPointer/EnrichInfo/internal/entity"
+)
+
+func init() {
+	render.Respond = Respond
+}
+
+// renderProblem renders the problem like a handler would and decodes the response.
+func renderProblem(t *testing.T, problem render.Renderer) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/v1/people/1?x=y", nil)
+	if err := render.Render(w, r, problem); err != nil {
+		t.Fatalf("render.Render() error = %v", err)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response body %q: %v", w.Body.String(), err)
+	}
+
+	return w, body
+}
+
+func TestErrorResponse(t *testing.T) {
+	validation := &entity.ValidationError{}
+	validation.Add("name", "must not be empty")
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantDetail bool
+	}{
+		{"person not found", fmt.Errorf("PersonRepo - GetPerson - row.Scan: %w", entity.ErrPersonNotFound), http.StatusNotFound, CodeNotFound, true},
+		{"duplicate person", &entity.DuplicatePersonError{ExistingID: 7}, http.StatusConflict, CodeConflict, true},
+		{"person modified", entity.ErrPersonModified, http.StatusPreconditionFailed, CodePreconditionFailed, true},
+		{"validation", validation, http.StatusUnprocessableEntity, CodeValidationFailed, true},
+		{"provider unavailable", entity.ErrProviderUnavailable, http.StatusServiceUnavailable, CodeProviderUnavailable, true},
+		{"provider bad response", entity.ErrProviderBadResponse, http.StatusBadGateway, CodeUpstreamUnavailable, true},
+		{"idempotency key in use", entity.ErrIdempotencyKeyInUse, http.StatusConflict, CodeIdempotencyKeyInUse, true},
+		{"idempotency key reused", entity.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, CodeIdempotencyKeyReused, true},
+		{"internal", fmt.Errorf("%w: connection refused", entity.ErrInternal), http.StatusInternalServerError, CodeInternal, false},
+		{"unknown", errors.New("boom"), http.StatusInternalServerError, CodeInternal, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, body := renderProblem(t, ErrorResponse(tt.err))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Content-Type"); got != _problemContentType {
+				t.Errorf("Content-Type = %q, want %q", got, _problemContentType)
+			}
+			if body["code"] != tt.wantCode {
+				t.Errorf("code = %v, want %v", body["code"], tt.wantCode)
+			}
+			if body["type"] != _problemTypePrefix+tt.wantCode {
+				t.Errorf("type = %v, want %v", body["type"], _problemTypePrefix+tt.wantCode)
+			}
+			if body["status"] != float64(tt.wantStatus) {
+				t.Errorf("status member = %v, want %d", body["status"], tt.wantStatus)
+			}
+			if body["instance"] != "/v1/people/1?x=y" {
+				t.Errorf("instance = %v, want /v1/people/1?x=y", body["instance"])
+			}
+			if _, ok := body["detail"]; ok != tt.wantDetail {
+				t.Errorf("detail = %v, want present %t", body["detail"], tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestErrorResponseMembers(t *testing.T) {
+	_, body := renderProblem(t, ErrorResponse(&entity.DuplicatePersonError{ExistingID: 7}))
+	if body["existing_id"] != float64(7) {
+		t.Errorf("existing_id = %v, want 7", body["existing_id"])
+	}
+
+	validation := &entity.ValidationError{}
+	validation.Add("name", "must not be empty")
+	validation.Add("age", "must be between 0 and 150")
+
+	_, body = renderProblem(t, ErrorResponse(validation))
+	fields, _ := body["errors"].([]any)
+	if len(fields) != 2 {
+		t.Fatalf("errors = %v, want 2 fields", body["errors"])
+	}
+	if field, _ := fields[1].(map[string]any); field["field"] != "age" {
+		t.Errorf("errors[1] = %v, want the age field", fields[1])
+	}
+}
+
+func TestErrorBind(t *testing.T) {
+	validation := &entity.ValidationError{}
+	validation.Add("name", "must not be empty")
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"validation", validation, http.StatusUnprocessableEntity, CodeValidationFailed},
+		{"wrapped validation", fmt.Errorf("bind: %w", validation), http.StatusUnprocessableEntity, CodeValidationFailed},
+		{"malformed JSON", errors.New("unexpected EOF"), http.StatusBadRequest, CodeBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, body := renderProblem(t, ErrorBind(tt.err))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if body["code"] != tt.wantCode {
+				t.Errorf("code = %v, want %v", body["code"], tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRespondNonProblem(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/v1/people/1", nil)
+	Respond(w, r, map[string]int{"id": 1})
+
+	if got := w.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
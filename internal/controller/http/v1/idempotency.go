@@ -0,0 +1,125 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/realPointer/EnrichInfo/internal/entity"
+	"github.com/realPointer/EnrichInfo/internal/service"
+	"github.com/realPointer/EnrichInfo/pkg/logger"
+)
+
+const (
+	_idempotencyKeyHeader     = "Idempotency-Key"
+	_idempotentReplayedHeader = "Idempotent-Replayed"
+	_maxIdempotencyKeyLength  = 255
+)
+
+// _replayedHeaders are stored with a response and sent again when it is replayed.
+var _replayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// idempotent makes requests with an Idempotency-Key header safe to retry: the
+// response to the first request is stored and replayed for every repeat.
+// Responses with a 5xx status aren't stored, so the request can be retried.
+func idempotent(idempotencyService service.Idempotency, l logger.Interface) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(_idempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > _maxIdempotencyKeyLength {
+				render.Render(w, r, ErrorInvalidRequest(errors.New("Idempotency-Key must be at most 255 characters long")))
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				render.Render(w, r, ErrorInvalidRequest(err))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			stored, err := idempotencyService.Begin(r.Context(), key, fingerprint(r, body))
+			if err != nil {
+				renderError(w, r, l, err, "Error beginning idempotent request %q", key)
+				return
+			}
+			if stored != nil {
+				replay(w, stored)
+				return
+			}
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			buf := &bytes.Buffer{}
+			ww.Tee(buf)
+
+			completed := false
+			defer func() {
+				if completed {
+					return
+				}
+				// Release the key even if the client is gone or the handler panicked.
+				if err := idempotencyService.Abort(context.WithoutCancel(r.Context()), key); err != nil {
+					l.Error("Error aborting idempotent request %q: %v", key, err)
+				}
+			}()
+
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if status >= http.StatusInternalServerError {
+				return
+			}
+
+			response := &entity.IdempotentResponse{
+				StatusCode: status,
+				Header:     make(map[string]string, len(_replayedHeaders)),
+				Body:       buf.Bytes(),
+			}
+			for _, name := range _replayedHeaders {
+				if value := ww.Header().Get(name); value != "" {
+					response.Header[name] = value
+				}
+			}
+
+			err = idempotencyService.Complete(context.WithoutCancel(r.Context()), key, response)
+			if err != nil {
+				l.Error("Error completing idempotent request %q: %v", key, err)
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+// fingerprint identifies a request by its method, path and body.
+func fingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// replay writes a stored response again.
+func replay(w http.ResponseWriter, response *entity.IdempotentResponse) {
+	for name, value := range response.Header {
+		w.Header().Set(name, value)
+	}
+	w.Header().Set(_idempotentReplayedHeader, strconv.FormatBool(true))
+	w.WriteHeader(response.StatusCode)
+	w.Write(response.Body) //nolint:errcheck // nothing left to do if the client is gone
+}
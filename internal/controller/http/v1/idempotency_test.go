@@ -0,0 +1,230 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/realPointer/EnrichInfo/internal/entity"
+	"github.com/realPointer/EnrichInfo/internal/repo"
+	"github.com/realPointer/EnrichInfo/internal/service/services"
+	"github.com/realPointer/EnrichInfo/pkg/logger"
+)
+
+// memoryIdempotencyRepo keeps the idempotency keys in memory. Expiry is ignored.
+type memoryIdempotencyRepo struct {
+	repo.Idempotency
+	mu      sync.Mutex
+	records map[string]*entity.IdempotencyRecord
+}
+
+func newMemoryIdempotencyRepo() *memoryIdempotencyRepo {
+	return &memoryIdempotencyRepo{records: map[string]*entity.IdempotencyRecord{}}
+}
+
+func (r *memoryIdempotencyRepo) ReserveIdempotencyKey(ctx context.Context, key, fingerprint string, expiresAt time.Time) (*entity.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if record, ok := r.records[key]; ok {
+		return record, nil
+	}
+	r.records[key] = &entity.IdempotencyRecord{Fingerprint: fingerprint}
+
+	return nil, nil
+}
+
+func (r *memoryIdempotencyRepo) SaveIdempotentResponse(ctx context.Context, key string, response *entity.IdempotentResponse, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records[key].Response = response
+	return nil
+}
+
+func (r *memoryIdempotencyRepo) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.records, key)
+	return nil
+}
+
+func (r *memoryIdempotencyRepo) has(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.records[key]
+	return ok
+}
+
+// countingHandler responds with status and counts how often it was called.
+type countingHandler struct {
+	status int
+	panics bool
+	calls  int
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.calls++
+	if h.panics {
+		panic("handler failed")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/v1/people/1")
+	w.Header().Set("ETag", `"1"`)
+	w.Header().Set("X-Request-Only", "yes")
+	w.WriteHeader(h.status)
+	w.Write([]byte(`{"id":1}`)) //nolint:errcheck // recorded by httptest
+}
+
+func newIdempotentHandler(next http.Handler) (http.Handler, *memoryIdempotencyRepo, *services.IdempotencyService) {
+	idempotencyRepo := newMemoryIdempotencyRepo()
+	idempotencyService := services.NewIdempotencyService(idempotencyRepo, time.Hour, time.Minute)
+
+	return idempotent(idempotencyService, logger.New("error"))(next), idempotencyRepo, idempotencyService
+}
+
+// serve sends a POST with the given key and body, recovering from a panic of the handler.
+func serve(h http.Handler, key, body string) (w *httptest.ResponseRecorder, panicked bool) {
+	r := httptest.NewRequest(http.MethodPost, "/v1/people", strings.NewReader(body))
+	if key != "" {
+		r.Header.Set(_idempotencyKeyHeader, key)
+	}
+	w = httptest.NewRecorder()
+
+	defer func() {
+		panicked = recover() != nil
+	}()
+	h.ServeHTTP(w, r)
+
+	return w, false
+}
+
+func TestIdempotentReplay(t *testing.T) {
+	next := &countingHandler{status: http.StatusCreated}
+	h, _, _ := newIdempotentHandler(next)
+
+	first, _ := serve(h, "key-1", `{"name":"Dmitriy"}`)
+	second, _ := serve(h, "key-1", `{"name":"Dmitriy"}`)
+
+	if next.calls != 1 {
+		t.Fatalf("handler called %d times, want 1", next.calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %q, want %d %q", second.Code, second.Body, http.StatusCreated, first.Body)
+	}
+	for _, name := range _replayedHeaders {
+		if got, want := second.Header().Get(name), first.Header().Get(name); got != want {
+			t.Errorf("replayed %s = %q, want %q", name, got, want)
+		}
+	}
+	if got := second.Header().Get("X-Request-Only"); got != "" {
+		t.Errorf("replayed X-Request-Only = %q, want it not stored", got)
+	}
+	if got := second.Header().Get(_idempotentReplayedHeader); got != "true" {
+		t.Errorf("%s = %q, want true", _idempotentReplayedHeader, got)
+	}
+	if got := first.Header().Get(_idempotentReplayedHeader); got != "" {
+		t.Errorf("first response %s = %q, want none", _idempotentReplayedHeader, got)
+	}
+}
+
+func TestIdempotentConflicts(t *testing.T) {
+	tests := []struct {
+		name       string
+		prepare    func(t *testing.T, h http.Handler, s *services.IdempotencyService)
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name: "different body",
+			prepare: func(t *testing.T, h http.Handler, s *services.IdempotencyService) {
+				serve(h, "key-1", `{"name":"Dmitriy"}`)
+			},
+			body:       `{"name":"Ivan"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   CodeIdempotencyKeyReused,
+		},
+		{
+			name: "in progress",
+			prepare: func(t *testing.T, h http.Handler, s *services.IdempotencyService) {
+				r := httptest.NewRequest(http.MethodPost, "/v1/people", nil)
+				if _, err := s.Begin(context.Background(), "key-1", fingerprint(r, []byte(`{"name":"Dmitriy"}`))); err != nil {
+					t.Fatalf("Begin() error = %v", err)
+				}
+			},
+			body:       `{"name":"Dmitriy"}`,
+			wantStatus: http.StatusConflict,
+			wantCode:   CodeIdempotencyKeyInUse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &countingHandler{status: http.StatusCreated}
+			h, _, s := newIdempotentHandler(next)
+			tt.prepare(t, h, s)
+			calls := next.calls
+
+			w, _ := serve(h, "key-1", tt.body)
+
+			if w.Code != tt.wantStatus || !strings.Contains(w.Body.String(), `"code":"`+tt.wantCode+`"`) {
+				t.Errorf("response = %d %s, want %d %s", w.Code, w.Body, tt.wantStatus, tt.wantCode)
+			}
+			if next.calls != calls {
+				t.Errorf("handler called despite the conflict")
+			}
+		})
+	}
+}
+
+func TestIdempotentReleasesKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     *countingHandler
+		wantPanic   bool
+		wantRetried bool
+	}{
+		{name: "server error", handler: &countingHandler{status: http.StatusBadGateway}, wantRetried: true},
+		{name: "panic", handler: &countingHandler{panics: true}, wantPanic: true, wantRetried: true},
+		{name: "client error is kept", handler: &countingHandler{status: http.StatusUnprocessableEntity}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, idempotencyRepo, _ := newIdempotentHandler(tt.handler)
+
+			if _, panicked := serve(h, "key-1", `{}`); panicked != tt.wantPanic {
+				t.Fatalf("panicked = %v, want %v", panicked, tt.wantPanic)
+			}
+			if idempotencyRepo.has("key-1") == tt.wantRetried {
+				t.Errorf("key stored = %v, want %v", idempotencyRepo.has("key-1"), !tt.wantRetried)
+			}
+
+			tt.handler.panics = false
+			serve(h, "key-1", `{}`)
+			if retried := tt.handler.calls == 2; retried != tt.wantRetried {
+				t.Errorf("retried = %v, want %v", retried, tt.wantRetried)
+			}
+		})
+	}
+}
+
+func TestIdempotentWithoutKey(t *testing.T) {
+	next := &countingHandler{status: http.StatusCreated}
+	h, _, _ := newIdempotentHandler(next)
+
+	serve(h, "", `{}`)
+	w, _ := serve(h, "", `{}`)
+
+	if next.calls != 2 || w.Header().Get(_idempotentReplayedHeader) != "" {
+		t.Errorf("handler called %d times, want requests without a key passed through", next.calls)
+	}
+}
@@ -2,9 +2,15 @@ package v1
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"mime"
 	"net/http"
+	"net/url"
+	"path"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
@@ -15,81 +21,148 @@ import (
 
 type peopleRoutes struct {
 	peopleService service.Person
+	maxPerPage    int
 	l             logger.Interface
 }
 
-func NewPeopleRouter(peopleService service.Person, l logger.Interface) http.Handler {
+func NewPeopleRouter(peopleService service.Person, idempotencyService service.Idempotency, maxPerPage int, l logger.Interface) http.Handler {
 	p := peopleRoutes{
 		peopleService: peopleService,
+		maxPerPage:    maxPerPage,
 		l:             l,
 	}
 	r := chi.NewRouter()
 
+	// Retried writes don't create people or call the providers twice
+	idempotentRequest := idempotent(idempotencyService, l)
+
 	r.Get("/", p.searchPeople)
-	r.Post("/", p.createPerson)
-	r.Put("/{id}", p.updatePerson)
+	r.With(idempotentRequest).Post("/", p.createPerson)
+	r.Get("/{id}", p.getPerson)
+	r.With(idempotentRequest).Put("/{id}", p.updatePerson)
+	r.With(idempotentRequest).Patch("/{id}", p.patchPerson)
 	r.Delete("/{id}", p.deletePerson)
 
 	return r
 }
 
 // @Summary Create person
-// @Description Get name, surname, patronymic, enrich with age, gender and nationality, and save to database
+// @Description Get name, surname, patronymic, enrich with age, gender and nationality, and save to database.
+// @Description In async enrichment mode the person is stored as pending and enriched in the background.
 // @Tags People
 // @Accept json
-// @Success 201
-// @Failure 400
+// @Produce json
+// @Param person body entity.PersonInput true "Person"
+// @Param Idempotency-Key header string false "Makes the request safe to retry; repeats get the first response"
+// @Success 201 {object} entity.EnrichedPerson
+// @Header 201 {string} Location "URL of the created person"
+// @Header 201 {string} ETag "Version of the person"
+// @Success 202 {object} entity.EnrichedPerson
+// @Header 202 {string} Location "URL of the created person"
+// @Header 202 {string} ETag "Version of the person"
+// @Failure 400 {object} ErrResponse
+// @Failure 409 {object} ErrResponse
+// @Header 409 {string} Location "URL of the already stored person"
+// @Failure 422 {object} ErrResponse
+// @Failure 500 {object} ErrResponse
+// @Failure 502 {object} ErrResponse
+// @Failure 503 {object} ErrResponse
 // @Router /people [post]
 func (p *peopleRoutes) createPerson(w http.ResponseWriter, r *http.Request) {
 	// Bind the request body to a PersonInput struct
 	person := &entity.PersonInput{}
 	if err := render.Bind(r, person); err != nil {
 		p.l.Debug("Error binding request body: %v", err)
-		render.Render(w, r, ErrorInvalidRequest(err))
+		render.Render(w, r, ErrorBind(err))
 		return
 	}
 
-	// Enrich the person
-	enrichedPerson, err := enrichPerson(person, p.l)
+	// Enrich and create the person using the peopleService
+	createdPerson, err := p.peopleService.CreatePerson(r.Context(), person)
 	if err != nil {
-		p.l.Debug("Error enriching person: %v", err)
-		render.Render(w, r, ErrorInvalidRequest(err))
+		// Point the client to the person it tried to create again
+		var dup *entity.DuplicatePersonError
+		if errors.As(err, &dup) {
+			w.Header().Set("Location", path.Join(r.URL.Path, strconv.Itoa(dup.ExistingID)))
+		}
+
+		renderError(w, r, p.l, err, "Error creating person")
 		return
 	}
 
-	// Create the person using the peopleService
-	err = p.peopleService.CreatePerson(r.Context(), enrichedPerson)
-	if err != nil {
-		p.l.Debug("Error creating person: %v", err)
-		render.Render(w, r, ErrorInvalidRequest(err))
+	// Point the client to the new resource
+	w.Header().Set("Location", path.Join(r.URL.Path, strconv.Itoa(createdPerson.ID)))
+	w.Header().Set("ETag", entity.ETag(createdPerson.Version))
+
+	// In async mode the person is enriched later, so it's only accepted for now
+	if createdPerson.EnrichmentStatus == entity.EnrichmentPending {
+		p.l.Info("Person accepted for enrichment: %v", createdPerson)
+		render.Status(r, http.StatusAccepted)
+		render.JSON(w, r, createdPerson)
 		return
 	}
 
-	// Log the success and set the response status to 201 Created
-	p.l.Info("Person created successfully: %v", enrichedPerson)
+	// Log the success and return the created person with status 201 Created
+	p.l.Info("Person created successfully: %v", createdPerson)
 	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, createdPerson)
 }
 
-// @Summary Update person
-// @Description Update person by id
+// @Summary Get person
+// @Description Get person by id
 // @Tags People
-// @Accept json
+// @Produce json
 // @Param id path int true "Person ID"
-// @Success 200
-// @Failure 400
-// @Router /people/{id} [put]
-func (p *peopleRoutes) updatePerson(w http.ResponseWriter, r *http.Request) {
+// @Success 200 {object} entity.EnrichedPerson
+// @Header 200 {string} ETag "Version of the person"
+// @Failure 400 {object} ErrResponse
+// @Failure 404 {object} ErrResponse
+// @Failure 500 {object} ErrResponse
+// @Router /people/{id} [get]
+func (p *peopleRoutes) getPerson(w http.ResponseWriter, r *http.Request) {
 	personId, err := getIdFromRequest(r)
 	if err != nil {
 		p.l.Debug("Error getting person ID from request: %v", err)
-		render.Render(w, r, ErrorNotFound(err))
+		render.Render(w, r, ErrorInvalidRequest(err))
 		return
 	}
 
-	// Check and get info if person with the given ID exists.
-	previousPerson, err := p.peopleService.GetPerson(r.Context(), personId)
+	// Get the person with the given ID from the database.
+	person, err := p.peopleService.GetPerson(r.Context(), personId)
 	if err != nil {
-		p.l.Debug("Error getting person with ID %d: %v", personId, err)
+		renderError(w, r, p.l, err, "Error getting person with ID %d", personId)
+		return
+	}
+
+	// Return JSON response with the person and its version
+	w.Header().Set("ETag", entity.ETag(person.Version))
+	render.JSON(w, r, person)
+}
+
+// @Summary Replace person
+// @Description Replace person by id. Attributes left out of the body are cleared; the person isn't re-enriched.
+// @Tags People
+// @Accept json
+// @Produce json
+// @Param id path int true "Person ID"
+// @Param person body entity.EnrichedPerson true "Person"
+// @Param Idempotency-Key header string false "Makes the request safe to retry; repeats get the first response"
+// @Param If-Match header string false "ETag the person must still have"
+// @Success 200 {object} entity.EnrichedPerson
+// @Header 200 {string} ETag "New version of the person"
+// @Failure 400 {object} ErrResponse
+// @Failure 404 {object} ErrResponse
+// @Failure 409 {object} ErrResponse
+// @Failure 412 {object} ErrResponse
+// @Failure 422 {object} ErrResponse
+// @Failure 500 {object} ErrResponse
+// @Failure 502 {object} ErrResponse
+// @Failure 503 {object} ErrResponse
+// @Router /people/{id} [put]
+func (p *peopleRoutes) updatePerson(w http.ResponseWriter, r *http.Request) {
+	personId, err := getIdFromRequest(r)
+	if err != nil {
+		p.l.Debug("Error getting person ID from request: %v", err)
 		render.Render(w, r, ErrorInvalidRequest(err))
 		return
 	}
@@ -98,167 +171,106 @@ func (p *peopleRoutes) updatePerson(w http.ResponseWriter, r *http.Request) {
 	person := &entity.EnrichedPerson{}
 	if err := render.Bind(r, person); err != nil {
 		p.l.Debug("Error binding request body: %v", err)
-		render.Render(w, r, ErrorInvalidRequest(err))
+		render.Render(w, r, ErrorBind(err))
 		return
 	}
 
-	// Update the enriched person with the entered data only.
-	// Как-то упростить эти конструкции. Какой-нибудь reflect?
-	if person.Name != "" {
-		previousPerson.Name = person.Name
-	}
-	if person.Surname != "" {
-		previousPerson.Surname = person.Surname
-	}
-	if person.Patronymic != "" {
-		previousPerson.Patronymic = person.Patronymic
-	}
-	if person.Age != 0 {
-		previousPerson.Age = person.Age
-	}
-	if person.Gender != "" {
-		previousPerson.Gender = person.Gender
-	}
-	if person.Nationality != "" {
-		previousPerson.Nationality = person.Nationality
-	}
-
-	// If the person's name is provided, re-enrich the person's information.
-	if person.Name != "" {
-		p.l.Debug("Re-enriching person with name: %s", person.Name)
-
-		// Enrich the person's information with the provided name.
-		reEnrichedPerson, err := enrichPerson(&entity.PersonInput{Name: previousPerson.Name, Surname: previousPerson.Surname, Patronymic: previousPerson.Patronymic}, p.l)
-		if err != nil {
-			p.l.Debug("Error re-enriching person: %v", err)
-			render.Render(w, r, ErrorInvalidRequest(err))
-			return
-		}
-
-		// Update the person's information with the re-enriched data.
-		err = p.peopleService.UpdatePerson(r.Context(), personId, reEnrichedPerson)
-		if err != nil {
-			p.l.Debug("Error updating person: %v", err)
-			render.Render(w, r, ErrorInvalidRequest(err))
-			return
-		}
-	} else {
-		p.l.Debug("Updating person without re-enriching")
-
-		// Update the person's information without re-enriching.
-		err = p.peopleService.UpdatePerson(r.Context(), personId, previousPerson)
-		if err != nil {
-			p.l.Debug("Error updating person: %v", err)
-			render.Render(w, r, ErrorInvalidRequest(err))
-			return
-		}
+	// Replace the person with the entered data.
+	updatedPerson, err := p.peopleService.UpdatePerson(r.Context(), personId, person, entity.ParseIfMatch(r.Header.Get("If-Match")))
+	if err != nil {
+		renderError(w, r, p.l, err, "Error updating person with ID %d", personId)
+		return
 	}
 
-	// Return a 200 status code.
-	render.Status(r, http.StatusOK)
+	// Return the stored person with its new version.
+	w.Header().Set("ETag", entity.ETag(updatedPerson.Version))
+	render.JSON(w, r, updatedPerson)
 }
 
-// enrichPerson enriches a person with additional information such as age, gender, and nationality.
-func enrichPerson(person *entity.PersonInput, l logger.Interface) (*entity.EnrichedPerson, error) {
-	// Create a new EnrichedPerson struct with the provided name, surname, and patronymic.
-	enrichedPerson := &entity.EnrichedPerson{
-		Name:       person.Name,
-		Surname:    person.Surname,
-		Patronymic: person.Patronymic,
-	}
-
-	// Get the age of the person using the agify.io API.
-	ageURL := fmt.Sprintf("https://api.agify.io/?name=%s", person.Name)
-	l.Debug("ageURL: ", ageURL)
-	ageResp, err := http.Get(ageURL)
+// @Summary Patch person
+// @Description Change some fields of a person by id with a JSON Merge Patch (RFC 7396); null clears a field.
+// @Description Changing the name or the country hint re-enriches the person, keeping the attributes set by the patch.
+// @Tags People
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path int true "Person ID"
+// @Param patch body entity.EnrichedPerson true "Merge patch of name, surname, patronymic, age, gender, nationality, country_hint and locale"
+// @Param Idempotency-Key header string false "Makes the request safe to retry; repeats get the first response"
+// @Param If-Match header string false "ETag the person must still have"
+// @Success 200 {object} entity.EnrichedPerson
+// @Header 200 {string} ETag "New version of the person"
+// @Failure 400 {object} ErrResponse
+// @Failure 404 {object} ErrResponse
+// @Failure 409 {object} ErrResponse
+// @Failure 412 {object} ErrResponse
+// @Failure 415 {object} ErrResponse
+// @Failure 422 {object} ErrResponse
+// @Failure 500 {object} ErrResponse
+// @Failure 502 {object} ErrResponse
+// @Failure 503 {object} ErrResponse
+// @Router /people/{id} [patch]
+func (p *peopleRoutes) patchPerson(w http.ResponseWriter, r *http.Request) {
+	personId, err := getIdFromRequest(r)
 	if err != nil {
-		l.Error("error while getting age: ", err)
-		return nil, err
-	}
-	defer ageResp.Body.Close()
-
-	var ageData struct {
-		Age int `json:"age"`
-	}
-	if err := json.NewDecoder(ageResp.Body).Decode(&ageData); err != nil {
-		l.Error("error while decoding age response: ", err)
-		return nil, err
+		p.l.Debug("Error getting person ID from request: %v", err)
+		render.Render(w, r, ErrorInvalidRequest(err))
+		return
 	}
 
-	// Add the age to the enrichedPerson struct.
-	enrichedPerson.Age = ageData.Age
-	l.Debug("age: ", enrichedPerson.Age)
-
-	// Get the gender of the person using the genderize.io API.
-	genderURL := fmt.Sprintf("https://api.genderize.io/?name=%s", person.Name)
-	l.Debug("genderURL: ", genderURL)
-	genderResp, err := http.Get(genderURL)
-	if err != nil {
-		l.Error("error while getting gender: ", err)
-		return nil, err
+	// Only merge patches are accepted, other patch formats mean something else.
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != entity.MergePatchContentType {
+		render.Render(w, r, ErrorUnsupportedMediaType(fmt.Errorf("content type must be %s", entity.MergePatchContentType)))
+		return
 	}
-	defer genderResp.Body.Close()
 
-	var genderData struct {
-		Gender string `json:"gender"`
-	}
-	if err := json.NewDecoder(genderResp.Body).Decode(&genderData); err != nil {
-		l.Error("error while decoding gender response: ", err)
-		return nil, err
+	// Decode the patch, which render.Bind can't do for this content type.
+	var body json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		p.l.Debug("Error decoding request body: %v", err)
+		render.Render(w, r, ErrorInvalidRequest(err))
+		return
 	}
-
-	// Add the gender to the enrichedPerson struct.
-	enrichedPerson.Gender = genderData.Gender
-	l.Debug("gender: ", enrichedPerson.Gender)
-
-	// Get the nationality of the person using the nationalize.io API.
-	nationalityURL := fmt.Sprintf("https://api.nationalize.io/?name=%s", person.Name)
-	l.Debug("nationalityURL: ", nationalityURL)
-	nationalityResp, err := http.Get(nationalityURL)
+	patch, err := entity.ParsePersonPatch(body)
 	if err != nil {
-		l.Error("error while getting nationality: ", err)
-		return nil, err
-	}
-	defer nationalityResp.Body.Close()
-
-	var nationalityData struct {
-		Country []struct {
-			Code string `json:"country_id"`
-		}
+		p.l.Debug("Error decoding request body: %v", err)
+		render.Render(w, r, ErrorBind(err))
+		return
 	}
-	err = json.NewDecoder(nationalityResp.Body).Decode(&nationalityData)
-	if err != nil {
-		l.Error("error while decoding nationality response: ", err)
-		return nil, err
+	if err := patch.Bind(r); err != nil {
+		p.l.Debug("Error binding request body: %v", err)
+		render.Render(w, r, ErrorBind(err))
+		return
 	}
 
-	// Add the nationality to the enrichedPerson struct.
-	if len(nationalityData.Country) != 0 {
-		personNationality := nationalityData.Country[0].Code
-		enrichedPerson.Nationality = personNationality
-	} else {
-		l.Error("no nationality found for ", enrichedPerson.Name)
-		return nil, fmt.Errorf("no nationality found for %s", enrichedPerson.Name)
+	// Patch the person, re-enriching it if the name has changed.
+	patchedPerson, err := p.peopleService.PatchPerson(r.Context(), personId, patch, entity.RequestLocale(r), entity.ParseIfMatch(r.Header.Get("If-Match")))
+	if err != nil {
+		renderError(w, r, p.l, err, "Error patching person with ID %d", personId)
+		return
 	}
-	l.Debug("nationality: ", enrichedPerson.Nationality)
-	l.Debug("enrichedPerson: ", enrichedPerson)
 
-	// Return the enrichedPerson struct and nil error.
-	return enrichedPerson, nil
+	// Return the stored person with its new version.
+	w.Header().Set("ETag", entity.ETag(patchedPerson.Version))
+	render.JSON(w, r, patchedPerson)
 }
 
 // @Summary Delete person
 // @Description Delete person by id
 // @Tags People
 // @Param id path int true "Person ID"
+// @Param If-Match header string false "ETag the person must still have"
 // @Success 200
-// @Failure 400
+// @Failure 400 {object} ErrResponse
+// @Failure 404 {object} ErrResponse
+// @Failure 412 {object} ErrResponse
+// @Failure 500 {object} ErrResponse
 // @Router /people/{id} [delete]
 func (p *peopleRoutes) deletePerson(w http.ResponseWriter, r *http.Request) {
 	personId, err := getIdFromRequest(r)
 	if err != nil {
 		p.l.Debug("Error getting person ID from request: %v", err)
-		render.Render(w, r, ErrorNotFound(err))
+		render.Render(w, r, ErrorInvalidRequest(err))
 		return
 	}
 
@@ -266,10 +278,9 @@ func (p *peopleRoutes) deletePerson(w http.ResponseWriter, r *http.Request) {
 	p.l.Info("Deleting person with ID %d", personId)
 
 	// Delete the person with the given ID from the database.
-	err = p.peopleService.DeletePerson(r.Context(), personId)
+	err = p.peopleService.DeletePerson(r.Context(), personId, entity.ParseIfMatch(r.Header.Get("If-Match")))
 	if err != nil {
-		p.l.Debug("Error deleting person with ID %d: %v", personId, err)
-		render.Render(w, r, ErrorInvalidRequest(err))
+		renderError(w, r, p.l, err, "Error deleting person with ID %d", personId)
 		return
 	}
 
@@ -283,9 +294,10 @@ func (p *peopleRoutes) deletePerson(w http.ResponseWriter, r *http.Request) {
 func getIdFromRequest(r *http.Request) (int, error) {
 	personIdStr := chi.URLParam(r, "id")
 
+	// IDs are assigned by a serial column, so they start at 1.
 	personId, err := strconv.Atoi(personIdStr)
-	if err != nil {
-		return 0, err
+	if err != nil || personId < 1 {
+		return 0, fmt.Errorf("person ID must be a positive integer, got %q", personIdStr)
 	}
 
 	return personId, nil
@@ -294,57 +306,218 @@ func getIdFromRequest(r *http.Request) (int, error) {
 // @Summary Search people
 // @Description Returns a list of people matching the specified search criteria
 // @Tags People
-// @Tags People
 // @Param name query string false "Name"
 // @Param surname query string false "Surname"
 // @Param patronymic query string false "Patronymic"
+// @Param name_prefix query string false "Name starts with"
+// @Param surname_ilike query string false "Surname contains, ignoring case"
 // @Param age query int false "Age"
-// @Param gender query string false "Gender"
-// @Param nationality query string false "Nationality"
-// @Param page query int false "Page"
-// @Param perPage query int false "Persons per page"
-// @Success 200
-// @Failure 400
+// @Param age_gte query int false "Age is at least"
+// @Param age_lte query int false "Age is at most"
+// @Param gender query string false "Gender" Enums(male, female)
+// @Param gender! query string false "Gender is not, as in gender!=male" Enums(male, female)
+// @Param nationality query string false "Comma-separated nationalities, any of which matches"
+// @Param sort query string false "Comma-separated fields to sort by, prefixed with - for descending: id, name, surname, patronymic, age, gender, nationality" default(id)
+// @Param page query int false "Page" minimum(1) default(1)
+// @Param perPage query int false "Persons per page, up to search.max_per_page" minimum(1) default(10)
+// @Param cursor query string false "next_cursor of the previous page; the filters and sort must stay the same"
+// @Param limit query int false "Persons per page after the cursor, up to search.max_per_page" minimum(1) default(10)
+// @Produce json
+// @Success 200 {object} entity.PeoplePage
+// @Header 200 {string} Link "Links to the next and previous pages"
+// @Failure 422 {object} ErrResponse
+// @Failure 500 {object} ErrResponse
 // @Router /people [get]
 func (p *peopleRoutes) searchPeople(w http.ResponseWriter, r *http.Request) {
 	// Get filters from query parameters
-	filters := map[string]string{}
-	queryParams := []string{"name", "surname", "patronymic", "age", "gender", "nationality"}
-	for _, param := range queryParams {
-		filters[param] = r.URL.Query().Get(param)
+	filter, err := personFilterFromQuery(r.URL.Query())
+	if err != nil {
+		renderError(w, r, p.l, err, "Error parsing search filters")
+		return
 	}
 
-	// Get page number from query parameters
-	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	sort, err := entity.ParsePersonSort(r.URL.Query().Get("sort"))
 	if err != nil {
-		page = 1
+		renderError(w, r, p.l, err, "Error parsing search sort")
+		return
 	}
 
-	// Get number of results per page from query parameters
-	perPage, err := strconv.Atoi(r.URL.Query().Get("perPage"))
+	query := r.URL.Query()
+	page, perPage, after, err := p.pageFromQuery(query)
 	if err != nil {
-		perPage = 10
+		renderError(w, r, p.l, err, "Error parsing search page")
+		return
+	}
+	if after != nil && query.Get("sort") == "" {
+		// The cursor remembers the sort of the pages before it.
+		sort = after.Sort
+	} else if after != nil && after.Sort.String() != sort.String() {
+		verr := &entity.ValidationError{}
+		verr.Add("cursor", "was issued for a different sort")
+		renderError(w, r, p.l, verr, "Error parsing search cursor")
+		return
+	}
+	if after != nil && after.Filter != filter.Hash() {
+		verr := &entity.ValidationError{}
+		verr.Add("cursor", "was issued for different filters")
+		renderError(w, r, p.l, verr, "Error parsing search cursor")
+		return
 	}
-
-	// Convert page and perPage to uint64
-	pageUint := uint64(page)
-	perPageUint := uint64(perPage)
 
 	// Log search parameters
-	p.l.Debug(fmt.Sprintf("searchPeople: filters=%v, page=%d, perPage=%d", filters, pageUint, perPageUint))
+	p.l.Debug(fmt.Sprintf("searchPeople: filter=%+v, sort=%+v, page=%d, perPage=%d", filter, sort, page, perPage))
 
 	// Search people based on filters, page number, and number of results per page
-	people, err := p.peopleService.SearchPeople(r.Context(), filters, pageUint, perPageUint)
+	people, err := p.peopleService.SearchPeople(r.Context(), filter, sort, after, page, perPage)
 	if err != nil {
 		// Return error response if there is an error while searching
-		p.l.Error(fmt.Sprintf("searchPeople: error=%v", err))
-		render.Render(w, r, ErrorInvalidRequest(err))
+		renderError(w, r, p.l, err, "Error searching people")
 		return
 	}
 
 	// Log search results
-	p.l.Debug(fmt.Sprintf("searchPeople: people=%v", people))
+	p.l.Debug(fmt.Sprintf("searchPeople: total=%d, people=%v", people.Total, people.Items))
+
+	people.Links = pageLinks(r.URL, people)
+	if link := linkHeader(people.Links); link != "" {
+		w.Header().Set("Link", link)
+	}
 
 	// Return JSON response with search results
 	render.JSON(w, r, people)
 }
+
+// pageFromQuery reads the requested page: a numbered one from page and
+// perPage, or the one after the cursor if cursor or limit is set.
+func (p *peopleRoutes) pageFromQuery(query url.Values) (page, perPage uint64, after *entity.PersonCursor, err error) {
+	verr := &entity.ValidationError{}
+	pageSizeParam := "perPage"
+	if query.Has("cursor") || query.Has("limit") {
+		// Cursor pages have no number.
+		pageSizeParam = "limit"
+		if query.Has("page") || query.Has("perPage") {
+			verr.Add("page", "can't be combined with cursor or limit")
+		}
+	} else {
+		page = 1
+		if value := intQueryParam(verr, query, "page"); value != nil {
+			if *value < 1 {
+				verr.Add("page", "must be at least 1")
+			} else {
+				page = uint64(*value)
+			}
+		}
+	}
+
+	perPage = uint64(min(10, p.maxPerPage))
+	if value := intQueryParam(verr, query, pageSizeParam); value != nil {
+		if *value < 1 || *value > p.maxPerPage {
+			verr.Add(pageSizeParam, fmt.Sprintf("must be between 1 and %d", p.maxPerPage))
+		} else {
+			perPage = uint64(*value)
+		}
+	}
+
+	// The offset of the page must fit into a PostgreSQL bigint.
+	if page > 1 && page-1 > math.MaxInt64/perPage {
+		verr.Add("page", fmt.Sprintf("must be at most %d", math.MaxInt64/perPage+1))
+	}
+
+	if query.Get("cursor") != "" {
+		cursor, err := entity.ParsePersonCursor(query.Get("cursor"))
+		if err != nil {
+			verr.Add("cursor", "is invalid")
+		}
+		after = cursor
+	}
+
+	return page, perPage, after, verr.Err()
+}
+
+// pageLinks returns the URLs of the page and of its neighbours, built from
+// the URL the page was requested with.
+func pageLinks(requestURL *url.URL, people *entity.PeoplePage) entity.PageLinks {
+	link := func(set map[string]string) string {
+		u := *requestURL
+		query := u.Query()
+		for param, value := range set {
+			query.Set(param, value)
+		}
+		u.RawQuery = query.Encode()
+		return u.RequestURI()
+	}
+
+	links := entity.PageLinks{Self: requestURL.RequestURI()}
+	switch {
+	case people.Page == 0:
+		// Cursor pages only link forward.
+		if people.NextCursor != "" {
+			links.Next = link(map[string]string{"cursor": people.NextCursor})
+		}
+	default:
+		if people.NextCursor != "" {
+			links.Next = link(map[string]string{"page": strconv.FormatUint(people.Page+1, 10)})
+		}
+		if people.Page > 1 {
+			links.Prev = link(map[string]string{"page": strconv.FormatUint(people.Page-1, 10)})
+		}
+	}
+
+	return links
+}
+
+// linkHeader formats the links to the neighbouring pages as an RFC 8288 Link header.
+func linkHeader(links entity.PageLinks) string {
+	header := []string{}
+	if links.Next != "" {
+		header = append(header, fmt.Sprintf(`<%s>; rel="next"`, links.Next))
+	}
+	if links.Prev != "" {
+		header = append(header, fmt.Sprintf(`<%s>; rel="prev"`, links.Prev))
+	}
+
+	return strings.Join(header, ", ")
+}
+
+// personFilterFromQuery reads the search filter from the query parameters.
+func personFilterFromQuery(query url.Values) (entity.PersonFilter, error) {
+	filter := entity.PersonFilter{
+		Name:         query.Get("name"),
+		Surname:      query.Get("surname"),
+		Patronymic:   query.Get("patronymic"),
+		NamePrefix:   query.Get("name_prefix"),
+		SurnameILike: query.Get("surname_ilike"),
+		Gender:       query.Get("gender"),
+		// "gender!=male" is parsed as the parameter "gender!" set to "male"
+		GenderNot: query.Get("gender!"),
+	}
+	if nationality := query.Get("nationality"); nationality != "" {
+		filter.Nationalities = strings.Split(nationality, ",")
+	}
+
+	verr := &entity.ValidationError{}
+	filter.Age = intQueryParam(verr, query, "age")
+	filter.AgeGTE = intQueryParam(verr, query, "age_gte")
+	filter.AgeLTE = intQueryParam(verr, query, "age_lte")
+	if err := verr.Err(); err != nil {
+		return filter, err
+	}
+
+	return filter, filter.Validate()
+}
+
+// intQueryParam returns the integer value of a query parameter, or nil if it isn't set.
+func intQueryParam(verr *entity.ValidationError, query url.Values, name string) *int {
+	value := query.Get(name)
+	if value == "" {
+		return nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		verr.Add(name, "must be an integer")
+		return nil
+	}
+
+	return &number
+}
@@ -0,0 +1,293 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/realPointer/EnrichInfo/internal/entity"
+	"github.com/realPointer/EnrichInfo/internal/service"
+	"github.com/realPointer/EnrichInfo/pkg/logger"
+)
+
+// stubPeopleService answers searches with page, remembering the arguments.
+type stubPeopleService struct {
+	service.Person
+	page *entity.PeoplePage
+
+	searched bool
+	filter   entity.PersonFilter
+	sort     entity.PersonSort
+	after    *entity.PersonCursor
+	pageNum  uint64
+	perPage  uint64
+}
+
+func (s *stubPeopleService) SearchPeople(ctx context.Context, filter entity.PersonFilter, sort entity.PersonSort, after *entity.PersonCursor, page, perPage uint64) (*entity.PeoplePage, error) {
+	s.searched = true
+	s.filter, s.sort, s.after, s.pageNum, s.perPage = filter, sort, after, page, perPage
+
+	result := *s.page
+	result.Page, result.PerPage = page, perPage
+
+	return &result, nil
+}
+
+// search sends a search with the query to a people router of up to maxPerPage
+// people per page and decodes the response.
+func search(t *testing.T, peopleService service.Person, maxPerPage int, query string) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+
+	h := NewPeopleRouter(peopleService, nil, maxPerPage, logger.New("error"))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?"+query, nil))
+
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response body %q: %v", w.Body.String(), err)
+	}
+
+	return w, body
+}
+
+// problemFields returns the invalid fields listed by a problem, with their messages.
+func problemFields(body map[string]any) map[string]string {
+	fields := map[string]string{}
+	errs, _ := body["errors"].([]any)
+	for _, e := range errs {
+		field, _ := e.(map[string]any)
+		fields[field["field"].(string)], _ = field["message"].(string)
+	}
+
+	return fields
+}
+
+func TestSearchPeopleCursorMismatch(t *testing.T) {
+	byAge, _ := entity.ParsePersonSort("-age")
+	byID, _ := entity.ParsePersonSort("")
+	last := &entity.EnrichedPerson{ID: 7}
+	smiths := entity.PersonFilter{Surname: "Smith", Nationalities: []string{"RU", "UA"}}
+
+	tests := []struct {
+		name        string
+		cursor      *entity.PersonCursor
+		query       string
+		wantStatus  int
+		wantMessage string
+		wantSort    string
+	}{
+		{
+			name:       "same filters",
+			cursor:     entity.NewPersonCursor(smiths, byAge, last),
+			query:      "surname=Smith&nationality=RU,UA",
+			wantStatus: http.StatusOK,
+			wantSort:   "-age,id",
+		},
+		{
+			name:       "reordered nationalities",
+			cursor:     entity.NewPersonCursor(smiths, byAge, last),
+			query:      "surname=+Smith&nationality=ua,RU&sort=-age",
+			wantStatus: http.StatusOK,
+			wantSort:   "-age,id",
+		},
+		{
+			name:        "different filters",
+			cursor:      entity.NewPersonCursor(smiths, byAge, last),
+			query:       "surname=Smith&nationality=RU",
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantMessage: "was issued for different filters",
+		},
+		{
+			name:        "filters dropped",
+			cursor:      entity.NewPersonCursor(smiths, byID, last),
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantMessage: "was issued for different filters",
+		},
+		{
+			name:        "different sort",
+			cursor:      entity.NewPersonCursor(entity.PersonFilter{}, byAge, last),
+			query:       "sort=age",
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantMessage: "was issued for a different sort",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			peopleService := &stubPeopleService{page: &entity.PeoplePage{Items: []*entity.EnrichedPerson{}}}
+			query := "cursor=" + tt.cursor.Encode()
+			if tt.query != "" {
+				query += "&" + tt.query
+			}
+
+			w, body := search(t, peopleService, 100, query)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantMessage != "" {
+				if got := problemFields(body)["cursor"]; got != tt.wantMessage {
+					t.Errorf("cursor error = %q, want %q", got, tt.wantMessage)
+				}
+				if peopleService.searched {
+					t.Errorf("searched despite the invalid cursor")
+				}
+				return
+			}
+			if peopleService.sort.String() != tt.wantSort || peopleService.after.After.ID != last.ID {
+				t.Errorf("searched by %v after %+v, want %s after the cursor", peopleService.sort, peopleService.after, tt.wantSort)
+			}
+		})
+	}
+}
+
+func TestSearchPeoplePage(t *testing.T) {
+	byID, _ := entity.ParsePersonSort("")
+	cursor := entity.NewPersonCursor(entity.PersonFilter{}, byID, &entity.EnrichedPerson{ID: 7}).Encode()
+
+	tests := []struct {
+		name        string
+		query       string
+		maxPerPage  int
+		wantPage    uint64
+		wantPerPage uint64
+		wantCursor  bool
+		wantFields  []string
+	}{
+		{name: "defaults", wantPage: 1, wantPerPage: 10},
+		{name: "default capped by the maximum", maxPerPage: 5, wantPage: 1, wantPerPage: 5},
+		{name: "numbered page", query: "page=3&perPage=100", wantPage: 3, wantPerPage: 100},
+		{name: "page zero", query: "page=0", wantFields: []string{"page"}},
+		{name: "negative page", query: "page=-1", wantFields: []string{"page"}},
+		{name: "page not a number", query: "page=two", wantFields: []string{"page"}},
+		{name: "no people per page", query: "perPage=0", wantFields: []string{"perPage"}},
+		{name: "perPage above the maximum", query: "perPage=101", wantFields: []string{"perPage"}},
+		{name: "every invalid parameter", query: "page=0&perPage=101", wantFields: []string{"page", "perPage"}},
+		{name: "last page within bigint", query: fmt.Sprintf("page=%d&perPage=10", math.MaxInt64/10+1), wantPage: math.MaxInt64/10 + 1, wantPerPage: 10},
+		{name: "offset overflows bigint", query: fmt.Sprintf("page=%d&perPage=10", math.MaxInt64/10+2), wantFields: []string{"page"}},
+		{name: "offset of single people", query: fmt.Sprintf("page=%d&perPage=1", math.MaxInt64), wantPage: math.MaxInt64, wantPerPage: 1},
+		{name: "limit", query: "limit=5", wantPage: 0, wantPerPage: 5},
+		{name: "limit above the maximum", query: "limit=101", wantFields: []string{"limit"}},
+		{name: "cursor", query: "cursor=" + cursor, wantPerPage: 10, wantCursor: true},
+		{name: "invalid cursor", query: "cursor=nope", wantFields: []string{"cursor"}},
+		{name: "cursor with page", query: "cursor=nope&page=2", wantFields: []string{"page", "cursor"}},
+		{name: "cursor with limit", query: "cursor=" + cursor + "&limit=5", wantPerPage: 5, wantCursor: true},
+		{name: "limit with perPage", query: "limit=5&perPage=5", wantFields: []string{"page"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxPerPage := tt.maxPerPage
+			if maxPerPage == 0 {
+				maxPerPage = 100
+			}
+			peopleService := &stubPeopleService{page: &entity.PeoplePage{Items: []*entity.EnrichedPerson{}}}
+
+			w, body := search(t, peopleService, maxPerPage, tt.query)
+
+			if tt.wantFields != nil {
+				fields := problemFields(body)
+				if w.Code != http.StatusUnprocessableEntity || len(fields) != len(tt.wantFields) {
+					t.Fatalf("response = %d %v, want 422 for %v", w.Code, fields, tt.wantFields)
+				}
+				for _, field := range tt.wantFields {
+					if _, ok := fields[field]; !ok {
+						t.Errorf("invalid fields = %v, want %s", fields, field)
+					}
+				}
+				return
+			}
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+			if peopleService.pageNum != tt.wantPage || peopleService.perPage != tt.wantPerPage {
+				t.Errorf("searched page %d of %d, want page %d of %d", peopleService.pageNum, peopleService.perPage, tt.wantPage, tt.wantPerPage)
+			}
+			if (peopleService.after != nil) != tt.wantCursor {
+				t.Errorf("searched after %+v, want cursor %v", peopleService.after, tt.wantCursor)
+			}
+		})
+	}
+}
+
+func TestSearchPeopleLinks(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		nextCursor string
+		wantNext   string
+		wantPrev   string
+	}{
+		{
+			name:       "first page",
+			query:      "surname=Smith&perPage=5",
+			nextCursor: "c2",
+			wantNext:   "/?page=2&perPage=5&surname=Smith",
+		},
+		{
+			name:       "middle page",
+			query:      "page=2&perPage=5",
+			nextCursor: "c3",
+			wantNext:   "/?page=3&perPage=5",
+			wantPrev:   "/?page=1&perPage=5",
+		},
+		{
+			name:     "last page",
+			query:    "page=3&perPage=5",
+			wantPrev: "/?page=2&perPage=5",
+		},
+		{
+			name:  "only page",
+			query: "perPage=5",
+		},
+		{
+			name:       "cursor page links only forward",
+			query:      "limit=5",
+			nextCursor: "c2",
+			wantNext:   "/?cursor=c2&limit=5",
+		},
+		{
+			name:  "last cursor page",
+			query: "limit=5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			peopleService := &stubPeopleService{page: &entity.PeoplePage{Items: []*entity.EnrichedPerson{}, NextCursor: tt.nextCursor}}
+
+			w, body := search(t, peopleService, 100, tt.query)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+
+			var wantLink []string
+			if tt.wantNext != "" {
+				wantLink = append(wantLink, `<`+tt.wantNext+`>; rel="next"`)
+			}
+			if tt.wantPrev != "" {
+				wantLink = append(wantLink, `<`+tt.wantPrev+`>; rel="prev"`)
+			}
+			if got, want := w.Header().Get("Link"), strings.Join(wantLink, ", "); got != want {
+				t.Errorf("Link = %q, want %q", got, want)
+			}
+
+			links, _ := body["links"].(map[string]any)
+			if links["self"] != "/?"+tt.query {
+				t.Errorf("links.self = %v, want %s", links["self"], "/?"+tt.query)
+			}
+			if next, _ := links["next"].(string); next != tt.wantNext {
+				t.Errorf("links.next = %q, want %q", next, tt.wantNext)
+			}
+			if prev, _ := links["prev"].(string); prev != tt.wantPrev {
+				t.Errorf("links.prev = %q, want %q", prev, tt.wantPrev)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
 	"github.com/realPointer/EnrichInfo/internal/service"
 	"github.com/realPointer/EnrichInfo/pkg/logger"
 
@@ -13,7 +14,10 @@ import (
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 )
 
-func NewRouter(handler chi.Router, l logger.Interface, services *service.Services) {
+func NewRouter(handler chi.Router, l logger.Interface, services *service.Services, maxPerPage int) {
+	// Errors are rendered as RFC 7807 problem details
+	render.Respond = Respond
+
 	handler.Use(middleware.Logger)
 	handler.Use(middleware.Recoverer)
 	handler.Use(middleware.Timeout(60 * time.Second))
@@ -27,6 +31,7 @@ func NewRouter(handler chi.Router, l logger.Interface, services *service.Service
 	))
 
 	handler.Route("/v1", func(r chi.Router) {
-		r.Mount("/people", NewPeopleRouter(services.Person, l))
+		r.Mount("/people", NewPeopleRouter(services.Person, services.Idempotency, maxPerPage, l))
+		r.Mount("/enrichment", NewEnrichmentRouter(services.Enrichment, l))
 	})
 }
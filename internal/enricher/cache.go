@@ -0,0 +1,113 @@
+package enricher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/realPointer/EnrichInfo/internal/entity"
+	"github.com/realPointer/EnrichInfo/internal/repo"
+	"github.com/realPointer/EnrichInfo/pkg/logger"
+	"github.com/realPointer/EnrichInfo/pkg/lru"
+)
+
+// Cache reports how enrichment requests were served.
+type Cache interface {
+	Stats() entity.CacheStats
+}
+
+type cacheKey struct {
+	name        string
+	countryHint string
+}
+
+// CachedEnricher consults an in-memory LRU and then the database before
+// falling back to the wrapped Enricher. Only complete enrichments are cached.
+type CachedEnricher struct {
+	next   Enricher
+	memory *lru.Cache[cacheKey, entity.Enrichment]
+	repo   repo.EnrichmentCache
+	ttl    time.Duration
+	l      logger.Interface
+
+	memoryHits   atomic.Int64
+	databaseHits atomic.Int64
+	misses       atomic.Int64
+}
+
+var (
+	_ Enricher = (*CachedEnricher)(nil)
+	_ Cache    = (*CachedEnricher)(nil)
+)
+
+func NewCachedEnricher(next Enricher, repo repo.EnrichmentCache, size int, ttl time.Duration, l logger.Interface) *CachedEnricher {
+	return &CachedEnricher{
+		next:   next,
+		memory: lru.New[cacheKey, entity.Enrichment](size, ttl),
+		repo:   repo,
+		ttl:    ttl,
+		l:      l,
+	}
+}
+
+func (c *CachedEnricher) Enrich(ctx context.Context, person *entity.PersonInput) (*entity.EnrichedPerson, error) {
+	key := cacheKey{
+		name:        strings.ToLower(person.Name),
+		countryHint: person.CountryHint,
+	}
+
+	if enrichment, ok := c.memory.Get(key); ok {
+		c.memoryHits.Add(1)
+		return newEnrichedPerson(person, &enrichment), nil
+	}
+
+	// A broken cache must not break enrichment, so database errors are only logged.
+	enrichment, err := c.repo.GetEnrichment(ctx, key.name, key.countryHint)
+	if err != nil {
+		c.l.Warn("CachedEnricher - Enrich - c.repo.GetEnrichment: %v", err)
+	}
+	if enrichment != nil {
+		c.databaseHits.Add(1)
+		c.memory.Add(key, *enrichment)
+		return newEnrichedPerson(person, enrichment), nil
+	}
+
+	c.misses.Add(1)
+
+	enrichedPerson, err := c.next.Enrich(ctx, person)
+	if err != nil {
+		return enrichedPerson, fmt.Errorf("CachedEnricher - Enrich - c.next.Enrich: %w", err)
+	}
+
+	enrichment = enrichedPerson.Enrichment()
+	c.memory.Add(key, *enrichment)
+
+	err = c.repo.SaveEnrichment(ctx, key.name, key.countryHint, enrichment, time.Now().Add(c.ttl))
+	if err != nil {
+		c.l.Warn("CachedEnricher - Enrich - c.repo.SaveEnrichment: %v", err)
+	}
+
+	return enrichedPerson, nil
+}
+
+func (c *CachedEnricher) Stats() entity.CacheStats {
+	return entity.CacheStats{
+		MemoryHits:   c.memoryHits.Load(),
+		DatabaseHits: c.databaseHits.Load(),
+		Misses:       c.misses.Load(),
+	}
+}
+
+func newEnrichedPerson(person *entity.PersonInput, enrichment *entity.Enrichment) *entity.EnrichedPerson {
+	enrichedPerson := &entity.EnrichedPerson{
+		Name:        person.Name,
+		Surname:     person.Surname,
+		Patronymic:  person.Patronymic,
+		CountryHint: person.CountryHint,
+	}
+	enrichedPerson.SetEnrichment(enrichment)
+
+	return enrichedPerson
+}
@@ -0,0 +1,61 @@
+package enricher
+
+import (
+	"context"
+
+	"github.com/realPointer/EnrichInfo/internal/entity"
+)
+
+// Thresholds are the minimum confidences required to keep an enriched attribute.
+type Thresholds struct {
+	// AgeMinCount is the minimum number of samples the age prediction is based on.
+	AgeMinCount               int
+	GenderMinProbability      float64
+	NationalityMinProbability float64
+}
+
+// ConfidenceEnricher leaves the attributes the wrapped Enricher isn't
+// confident enough about unknown and lists them in LowConfidence.
+// It sits above the cache, so changing the thresholds applies to cached
+// enrichments as well.
+type ConfidenceEnricher struct {
+	next       Enricher
+	thresholds Thresholds
+}
+
+var _ Enricher = (*ConfidenceEnricher)(nil)
+
+func NewConfidenceEnricher(next Enricher, thresholds Thresholds) *ConfidenceEnricher {
+	return &ConfidenceEnricher{
+		next:       next,
+		thresholds: thresholds,
+	}
+}
+
+func (c *ConfidenceEnricher) Enrich(ctx context.Context, person *entity.PersonInput) (*entity.EnrichedPerson, error) {
+	enrichedPerson, err := c.next.Enrich(ctx, person)
+	if err != nil {
+		return enrichedPerson, err
+	}
+
+	var lowConfidence []string
+
+	if enrichedPerson.Age == nil || enrichedPerson.AgeCount < c.thresholds.AgeMinCount {
+		enrichedPerson.Age = nil
+		lowConfidence = append(lowConfidence, entity.AttributeAge)
+	}
+
+	if enrichedPerson.Gender == nil || enrichedPerson.GenderProbability < c.thresholds.GenderMinProbability {
+		enrichedPerson.Gender = nil
+		lowConfidence = append(lowConfidence, entity.AttributeGender)
+	}
+
+	if len(enrichedPerson.Nationalities) == 0 || enrichedPerson.Nationalities[0].Probability < c.thresholds.NationalityMinProbability {
+		enrichedPerson.Nationality = nil
+		lowConfidence = append(lowConfidence, entity.AttributeNationality)
+	}
+
+	enrichedPerson.LowConfidence = lowConfidence
+
+	return enrichedPerson, nil
+}
@@ -0,0 +1,116 @@
+package enricher
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/realPointer/EnrichInfo/internal/entity"
+)
+
+// stubEnricher returns a copy of person, or err.
+type stubEnricher struct {
+	person entity.EnrichedPerson
+	err    error
+}
+
+func (e *stubEnricher) Enrich(ctx context.Context, person *entity.PersonInput) (*entity.EnrichedPerson, error) {
+	enrichedPerson := e.person
+	return &enrichedPerson, e.err
+}
+
+func TestConfidenceEnricher(t *testing.T) {
+	thresholds := Thresholds{AgeMinCount: 10, GenderMinProbability: 0.8, NationalityMinProbability: 0.5}
+	age, gender, nationality := 42, entity.GenderMale, "RU"
+	confident := entity.EnrichedPerson{
+		Name:              "Dmitriy",
+		Age:               &age,
+		AgeCount:          10,
+		Gender:            &gender,
+		GenderProbability: 0.8,
+		Nationality:       &nationality,
+		Nationalities:     []entity.NationalityProbability{{CountryID: "RU", Probability: 0.5}},
+	}
+
+	tests := []struct {
+		name              string
+		modify            func(p *entity.EnrichedPerson)
+		wantLowConfidence []string
+	}{
+		{
+			name:   "at the thresholds",
+			modify: func(p *entity.EnrichedPerson) {},
+		},
+		{
+			name:              "too few age samples",
+			modify:            func(p *entity.EnrichedPerson) { p.AgeCount = 9 },
+			wantLowConfidence: []string{entity.AttributeAge},
+		},
+		{
+			name:              "improbable gender",
+			modify:            func(p *entity.EnrichedPerson) { p.GenderProbability = 0.79 },
+			wantLowConfidence: []string{entity.AttributeGender},
+		},
+		{
+			name:              "improbable nationality",
+			modify:            func(p *entity.EnrichedPerson) { p.Nationalities[0].Probability = 0.49 },
+			wantLowConfidence: []string{entity.AttributeNationality},
+		},
+		{
+			name: "unknown attributes",
+			modify: func(p *entity.EnrichedPerson) {
+				p.Age, p.Gender, p.Nationality, p.Nationalities = nil, nil, nil, nil
+			},
+			wantLowConfidence: []string{entity.AttributeAge, entity.AttributeGender, entity.AttributeNationality},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			person := confident
+			person.Nationalities = append([]entity.NationalityProbability(nil), confident.Nationalities...)
+			tt.modify(&person)
+
+			c := NewConfidenceEnricher(&stubEnricher{person: person}, thresholds)
+			got, err := c.Enrich(context.Background(), _input)
+			if err != nil {
+				t.Fatalf("Enrich() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got.LowConfidence, tt.wantLowConfidence) {
+				t.Errorf("LowConfidence = %v, want %v", got.LowConfidence, tt.wantLowConfidence)
+			}
+			for attribute, known := range map[string]bool{
+				entity.AttributeAge:         got.Age != nil,
+				entity.AttributeGender:      got.Gender != nil,
+				entity.AttributeNationality: got.Nationality != nil,
+			} {
+				low := false
+				for _, a := range tt.wantLowConfidence {
+					low = low || a == attribute
+				}
+				if known == low {
+					t.Errorf("%s known = %v, want %v", attribute, known, !low)
+				}
+			}
+			if len(got.Nationalities) != len(person.Nationalities) {
+				t.Errorf("Nationalities = %v, want the candidates kept", got.Nationalities)
+			}
+		})
+	}
+}
+
+func TestConfidenceEnricherError(t *testing.T) {
+	age := 42
+	partial := entity.EnrichedPerson{Name: "Dmitriy", Age: &age, AgeCount: 1}
+	c := NewConfidenceEnricher(&stubEnricher{person: partial, err: errGenderProvider}, Thresholds{AgeMinCount: 10})
+
+	got, err := c.Enrich(context.Background(), _input)
+	if !errors.Is(err, errGenderProvider) {
+		t.Fatalf("Enrich() error = %v, want %v", err, errGenderProvider)
+	}
+	if got == nil || got.Age == nil || got.LowConfidence != nil {
+		t.Errorf("Enrich() = %+v, want the partial person passed through unfiltered", got)
+	}
+}
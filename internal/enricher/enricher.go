@@ -0,0 +1,126 @@
+package enricher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/realPointer/EnrichInfo/internal/entity"
+	"github.com/realPointer/EnrichInfo/pkg/logger"
+)
+
+// AgeEnricher predicts the age of a name, optionally localized to a country.
+type AgeEnricher interface {
+	EnrichAge(ctx context.Context, name, countryID string) (*entity.AgePrediction, error)
+}
+
+// GenderEnricher predicts the gender of a name, optionally localized to a country.
+type GenderEnricher interface {
+	EnrichGender(ctx context.Context, name, countryID string) (*entity.GenderPrediction, error)
+}
+
+// NationalityEnricher predicts the candidate nationalities of a name, the most likely first.
+// An empty list means the nationality is unknown.
+type NationalityEnricher interface {
+	EnrichNationality(ctx context.Context, name string) ([]entity.NationalityProbability, error)
+}
+
+const _defaultTimeout = 10 * time.Second
+
+// Enricher enriches a person with additional information such as age, gender, and nationality.
+type Enricher interface {
+	Enrich(ctx context.Context, person *entity.PersonInput) (*entity.EnrichedPerson, error)
+}
+
+// Enrichers combines one provider per attribute into an Enricher.
+type Enrichers struct {
+	AgeEnricher
+	GenderEnricher
+	NationalityEnricher
+
+	timeout time.Duration
+	l       logger.Interface
+}
+
+var _ Enricher = (*Enrichers)(nil)
+
+func NewEnrichers(age AgeEnricher, gender GenderEnricher, nationality NationalityEnricher, l logger.Interface, opts ...Option) *Enrichers {
+	e := &Enrichers{
+		AgeEnricher:         age,
+		GenderEnricher:      gender,
+		NationalityEnricher: nationality,
+		timeout:             _defaultTimeout,
+		l:                   l,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// Enrich queries all providers concurrently under a shared deadline derived from ctx.
+// On error the returned person still holds every attribute that was enriched successfully.
+func (e *Enrichers) Enrich(ctx context.Context, person *entity.PersonInput) (*entity.EnrichedPerson, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	enrichedPerson := &entity.EnrichedPerson{
+		Name:        person.Name,
+		Surname:     person.Surname,
+		Patronymic:  person.Patronymic,
+		CountryHint: person.CountryHint,
+	}
+
+	var (
+		wg                                sync.WaitGroup
+		ageErr, genderErr, nationalityErr error
+	)
+
+	// Every goroutine writes to its own field, so no further synchronization is needed.
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		age, err := e.EnrichAge(ctx, person.Name, person.CountryHint)
+		if err != nil {
+			ageErr = fmt.Errorf("Enrichers - Enrich - e.EnrichAge: %w", err)
+			return
+		}
+		enrichedPerson.Age = age.Age
+		enrichedPerson.AgeCount = age.Count
+	}()
+	go func() {
+		defer wg.Done()
+		gender, err := e.EnrichGender(ctx, person.Name, person.CountryHint)
+		if err != nil {
+			genderErr = fmt.Errorf("Enrichers - Enrich - e.EnrichGender: %w", err)
+			return
+		}
+		enrichedPerson.Gender = gender.Gender
+		enrichedPerson.GenderProbability = gender.Probability
+	}()
+	go func() {
+		defer wg.Done()
+		nationalities, err := e.EnrichNationality(ctx, person.Name)
+		if err != nil {
+			nationalityErr = fmt.Errorf("Enrichers - Enrich - e.EnrichNationality: %w", err)
+			return
+		}
+		if len(nationalities) > 0 {
+			enrichedPerson.Nationality = &nationalities[0].CountryID
+		}
+		enrichedPerson.Nationalities = nationalities
+	}()
+	wg.Wait()
+
+	e.l.Debug("enrichedPerson: %v", enrichedPerson)
+
+	if err := errors.Join(ageErr, genderErr, nationalityErr); err != nil {
+		return enrichedPerson, err
+	}
+
+	return enrichedPerson, nil
+}
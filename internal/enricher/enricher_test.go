@@ -0,0 +1,186 @@
+package enricher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/realPointer/EnrichInfo/internal/entity"
+	"github.com/realPointer/EnrichInfo/pkg/logger"
+)
+
+var (
+	errAgeProvider    = errors.New("age provider failed")
+	errGenderProvider = errors.New("gender provider failed")
+)
+
+// stubProviders predicts fixed attributes. If barrier is set, every provider
+// waits until all of them have been called, which only happens if they run
+// concurrently.
+type stubProviders struct {
+	age            *entity.AgePrediction
+	gender         *entity.GenderPrediction
+	nationalities  []entity.NationalityProbability
+	ageErr         error
+	genderErr      error
+	nationalityErr error
+
+	barrier *sync.WaitGroup
+	all     chan struct{}
+}
+
+func (s *stubProviders) wait(ctx context.Context) error {
+	if s.barrier == nil {
+		return nil
+	}
+
+	s.barrier.Done()
+	select {
+	case <-s.all:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *stubProviders) EnrichAge(ctx context.Context, name, countryID string) (*entity.AgePrediction, error) {
+	if err := s.wait(ctx); err != nil {
+		return nil, err
+	}
+	return s.age, s.ageErr
+}
+
+func (s *stubProviders) EnrichGender(ctx context.Context, name, countryID string) (*entity.GenderPrediction, error) {
+	if err := s.wait(ctx); err != nil {
+		return nil, err
+	}
+	return s.gender, s.genderErr
+}
+
+func (s *stubProviders) EnrichNationality(ctx context.Context, name string) ([]entity.NationalityProbability, error) {
+	if err := s.wait(ctx); err != nil {
+		return nil, err
+	}
+	return s.nationalities, s.nationalityErr
+}
+
+func newStubProviders() *stubProviders {
+	age, gender := 42, entity.GenderMale
+	return &stubProviders{
+		age:           &entity.AgePrediction{Age: &age, Count: 100},
+		gender:        &entity.GenderPrediction{Gender: &gender, Probability: 0.9},
+		nationalities: []entity.NationalityProbability{{CountryID: "RU", Probability: 0.6}, {CountryID: "UA", Probability: 0.2}},
+	}
+}
+
+var _input = &entity.PersonInput{Name: "Dmitriy", Surname: "Ushakov", CountryHint: "RU"}
+
+func TestEnrichersConcurrent(t *testing.T) {
+	providers := newStubProviders()
+	providers.barrier = &sync.WaitGroup{}
+	providers.barrier.Add(3)
+	providers.all = make(chan struct{})
+	go func() {
+		providers.barrier.Wait()
+		close(providers.all)
+	}()
+
+	e := NewEnrichers(providers, providers, providers, logger.New("error"), Timeout(time.Second))
+	person, err := e.Enrich(context.Background(), _input)
+	if err != nil {
+		t.Fatalf("Enrich() error = %v, want the providers to run concurrently", err)
+	}
+
+	if person.Name != "Dmitriy" || person.Surname != "Ushakov" || person.CountryHint != "RU" {
+		t.Errorf("Enrich() = %+v, want the input names kept", person)
+	}
+	if *person.Age != 42 || person.AgeCount != 100 || *person.Gender != entity.GenderMale || *person.Nationality != "RU" {
+		t.Errorf("Enrich() = %+v, want every attribute enriched", person)
+	}
+}
+
+func TestEnrichersPartial(t *testing.T) {
+	tests := []struct {
+		name            string
+		modify          func(s *stubProviders)
+		wantErrs        []error
+		wantAge         bool
+		wantGender      bool
+		wantNationality bool
+	}{
+		{
+			name:            "all succeed",
+			modify:          func(s *stubProviders) {},
+			wantAge:         true,
+			wantGender:      true,
+			wantNationality: true,
+		},
+		{
+			name:            "age fails",
+			modify:          func(s *stubProviders) { s.ageErr = errAgeProvider },
+			wantErrs:        []error{errAgeProvider},
+			wantGender:      true,
+			wantNationality: true,
+		},
+		{
+			name: "age and gender fail",
+			modify: func(s *stubProviders) {
+				s.ageErr = errAgeProvider
+				s.genderErr = entity.ErrProviderUnavailable
+			},
+			wantErrs:        []error{errAgeProvider, entity.ErrProviderUnavailable},
+			wantNationality: true,
+		},
+		{
+			name: "all fail",
+			modify: func(s *stubProviders) {
+				s.ageErr = errAgeProvider
+				s.genderErr = errGenderProvider
+				s.nationalityErr = entity.ErrProviderBadResponse
+			},
+			wantErrs: []error{errAgeProvider, errGenderProvider, entity.ErrProviderBadResponse},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			providers := newStubProviders()
+			tt.modify(providers)
+			if providers.ageErr != nil {
+				providers.age = nil
+			}
+			if providers.genderErr != nil {
+				providers.gender = nil
+			}
+			if providers.nationalityErr != nil {
+				providers.nationalities = nil
+			}
+
+			e := NewEnrichers(providers, providers, providers, logger.New("error"))
+			person, err := e.Enrich(context.Background(), _input)
+
+			if (err != nil) != (len(tt.wantErrs) > 0) {
+				t.Fatalf("Enrich() error = %v, want %v", err, tt.wantErrs)
+			}
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("Enrich() error = %v, want it to wrap %v", err, want)
+				}
+			}
+			if person == nil {
+				t.Fatal("Enrich() = nil, want the partial person")
+			}
+			if (person.Age != nil) != tt.wantAge {
+				t.Errorf("age = %v, want enriched %v", person.Age, tt.wantAge)
+			}
+			if (person.Gender != nil) != tt.wantGender {
+				t.Errorf("gender = %v, want enriched %v", person.Gender, tt.wantGender)
+			}
+			if (person.Nationality != nil) != tt.wantNationality {
+				t.Errorf("nationality = %v, want enriched %v", person.Nationality, tt.wantNationality)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package enricher
+
+import "time"
+
+type Option func(*Enrichers)
+
+func Timeout(timeout time.Duration) Option {
+	return func(e *Enrichers) {
+		e.timeout = timeout
+	}
+}
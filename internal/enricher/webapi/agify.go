@@ -0,0 +1,38 @@
+package webapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/realPointer/EnrichInfo/internal/entity"
+)
+
+// Agify predicts the age of a person using the agify.io API.
+type Agify struct {
+	url    string
+	apiKey string
+	client Doer
+}
+
+func NewAgify(url, apiKey string, client Doer) *Agify {
+	return &Agify{
+		url:    url,
+		apiKey: apiKey,
+		client: client,
+	}
+}
+
+func (a *Agify) EnrichAge(ctx context.Context, name, countryID string) (*entity.AgePrediction, error) {
+	var ageData struct {
+		Count int  `json:"count"`
+		Age   *int `json:"age"`
+	}
+	if err := getJSON(ctx, a.client, a.url, a.apiKey, name, countryID, &ageData); err != nil {
+		return nil, fmt.Errorf("Agify - EnrichAge - getJSON: %w", err)
+	}
+
+	return &entity.AgePrediction{
+		Age:   ageData.Age,
+		Count: ageData.Count,
+	}, nil
+}
@@ -0,0 +1,38 @@
+package webapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/realPointer/EnrichInfo/internal/entity"
+)
+
+// Genderize predicts the gender of a person using the genderize.io API.
+type Genderize struct {
+	url    string
+	apiKey string
+	client Doer
+}
+
+func NewGenderize(url, apiKey string, client Doer) *Genderize {
+	return &Genderize{
+		url:    url,
+		apiKey: apiKey,
+		client: client,
+	}
+}
+
+func (g *Genderize) EnrichGender(ctx context.Context, name, countryID string) (*entity.GenderPrediction, error) {
+	var genderData struct {
+		Gender      *string `json:"gender"`
+		Probability float64 `json:"probability"`
+	}
+	if err := getJSON(ctx, g.client, g.url, g.apiKey, name, countryID, &genderData); err != nil {
+		return nil, fmt.Errorf("Genderize - EnrichGender - getJSON: %w", err)
+	}
+
+	return &entity.GenderPrediction{
+		Gender:      genderData.Gender,
+		Probability: genderData.Probability,
+	}, nil
+}
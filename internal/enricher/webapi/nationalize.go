@@ -0,0 +1,42 @@
+package webapi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/realPointer/EnrichInfo/internal/entity"
+)
+
+// Nationalize predicts the nationality of a person using the nationalize.io API.
+type Nationalize struct {
+	url    string
+	apiKey string
+	client Doer
+}
+
+func NewNationalize(url, apiKey string, client Doer) *Nationalize {
+	return &Nationalize{
+		url:    url,
+		apiKey: apiKey,
+		client: client,
+	}
+}
+
+// EnrichNationality returns the candidate nationalities sorted by probability, the most likely first.
+// The list is empty if the API has no data for the name.
+func (n *Nationalize) EnrichNationality(ctx context.Context, name string) ([]entity.NationalityProbability, error) {
+	var nationalityData struct {
+		Country []entity.NationalityProbability `json:"country"`
+	}
+	if err := getJSON(ctx, n.client, n.url, n.apiKey, name, "", &nationalityData); err != nil {
+		return nil, fmt.Errorf("Nationalize - EnrichNationality - getJSON: %w", err)
+	}
+
+	// The API already ranks the countries, but don't rely on it.
+	sort.SliceStable(nationalityData.Country, func(i, j int) bool {
+		return nationalityData.Country[i].Probability > nationalityData.Country[j].Probability
+	})
+
+	return nationalityData.Country, nil
+}
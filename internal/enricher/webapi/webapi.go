@@ -0,0 +1,59 @@
+package webapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/realPointer/EnrichInfo/internal/entity"
+)
+
+// Doer sends HTTP requests. It is satisfied by *http.Client and *httpclient.Client.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// getJSON requests baseURL with the given name and decodes the JSON response into v.
+// The API key and the country hint are only sent when they are set.
+func getJSON(ctx context.Context, client Doer, baseURL, apiKey, name, countryID string, v any) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("url.Parse: %w", err)
+	}
+
+	query := u.Query()
+	query.Set("name", name)
+	if apiKey != "" {
+		query.Set("apikey", apiKey)
+	}
+	if countryID != "" {
+		query.Set("country_id", countryID)
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: client.Do: %w", entity.ErrProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		return fmt.Errorf("%w: status code %d from %s", entity.ErrProviderUnavailable, resp.StatusCode, u.Host)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("%w: status code %d from %s", entity.ErrProviderBadResponse, resp.StatusCode, u.Host)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("%w: json.Decode: %w", entity.ErrProviderBadResponse, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package entity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// PersonCursor is the position in a sorted search the next page starts after:
+// the sort key of the last person of the previous page.
+type PersonCursor struct {
+	// Filter is the PersonFilter.Hash of the search the cursor belongs to.
+	Filter string
+	Sort   PersonSort
+	// After holds the sorted fields of the last person, the others are zero.
+	After *EnrichedPerson
+}
+
+// personCursorDoc is the JSON document an encoded cursor is made of.
+type personCursorDoc struct {
+	Filter string                     `json:"filter"`
+	Sort   string                     `json:"sort"`
+	Key    map[string]json.RawMessage `json:"key"`
+}
+
+// NewPersonCursor returns the cursor of the page of the filtered search that ends with last.
+func NewPersonCursor(filter PersonFilter, sort PersonSort, last *EnrichedPerson) *PersonCursor {
+	return &PersonCursor{Filter: filter.Hash(), Sort: sort, After: last}
+}
+
+// ParsePersonCursor decodes a cursor returned by Encode. Cursors are opaque to
+// clients, so every malformed one is reported the same way.
+func ParsePersonCursor(s string) (*PersonCursor, error) {
+	invalid := &ValidationError{}
+	invalid.Add("cursor", "is invalid")
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, invalid
+	}
+
+	doc := personCursorDoc{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, invalid
+	}
+
+	sort, err := ParsePersonSort(doc.Sort)
+	if err != nil || len(doc.Key) != len(sort) || doc.Filter == "" {
+		return nil, invalid
+	}
+
+	// The key members are named after the JSON members of a person.
+	key, err := json.Marshal(doc.Key)
+	if err != nil {
+		return nil, invalid
+	}
+	after := &EnrichedPerson{}
+	if err := json.Unmarshal(key, after); err != nil {
+		return nil, invalid
+	}
+	for _, field := range sort {
+		if _, ok := doc.Key[field.Field]; !ok {
+			return nil, invalid
+		}
+	}
+
+	return &PersonCursor{Filter: doc.Filter, Sort: sort, After: after}, nil
+}
+
+// Encode returns the opaque string representation of the cursor.
+func (c *PersonCursor) Encode() string {
+	doc := personCursorDoc{Filter: c.Filter, Sort: c.Sort.String(), Key: map[string]json.RawMessage{}}
+	for i, value := range c.Key() {
+		doc.Key[c.Sort[i].Field], _ = json.Marshal(value)
+	}
+
+	data, _ := json.Marshal(doc)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Key returns the values of the sorted fields of the last person, in the
+// order of the sort. Unknown values are nil.
+func (c *PersonCursor) Key() []any {
+	key := make([]any, 0, len(c.Sort))
+	for _, field := range c.Sort {
+		key = append(key, sortValue(c.After, field.Field))
+	}
+
+	return key
+}
+
+// sortValue returns the value of a sortable field of the person.
+func sortValue(person *EnrichedPerson, field string) any {
+	switch field {
+	case "id":
+		return person.ID
+	case "name":
+		return person.Name
+	case "surname":
+		return person.Surname
+	case "patronymic":
+		return person.Patronymic
+	case "age":
+		if person.Age != nil {
+			return *person.Age
+		}
+	case "gender":
+		if person.Gender != nil {
+			return *person.Gender
+		}
+	case "nationality":
+		if person.Nationality != nil {
+			return *person.Nationality
+		}
+	}
+
+	return nil
+}
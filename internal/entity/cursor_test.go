@@ -0,0 +1,132 @@
+package entity
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPersonCursorRoundTrip(t *testing.T) {
+	age := 30
+	gender := GenderFemale
+
+	tests := []struct {
+		name    string
+		sort    string
+		person  *EnrichedPerson
+		wantKey []any
+	}{
+		{
+			name:    "id only",
+			sort:    "",
+			person:  &EnrichedPerson{ID: 7},
+			wantKey: []any{7},
+		},
+		{
+			name:    "known values",
+			sort:    "-age,surname,gender",
+			person:  &EnrichedPerson{ID: 7, Surname: "Smith", Age: &age, Gender: &gender},
+			wantKey: []any{30, "Smith", "female", 7},
+		},
+		{
+			name:    "unknown values are nil",
+			sort:    "age,nationality",
+			person:  &EnrichedPerson{ID: 7},
+			wantKey: []any{nil, nil, 7},
+		},
+		{
+			name:    "strings with JSON and SQL metacharacters",
+			sort:    "name",
+			person:  &EnrichedPerson{ID: 1, Name: `O'Neil "x" %_\`},
+			wantKey: []any{`O'Neil "x" %_\`, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sort, err := ParsePersonSort(tt.sort)
+			if err != nil {
+				t.Fatalf("ParsePersonSort(%q) error = %v", tt.sort, err)
+			}
+
+			filter := PersonFilter{Surname: "Smith", Nationalities: []string{"RU"}}
+			cursor, err := ParsePersonCursor(NewPersonCursor(filter, sort, tt.person).Encode())
+			if err != nil {
+				t.Fatalf("ParsePersonCursor() error = %v", err)
+			}
+
+			if cursor.Filter != filter.Hash() {
+				t.Errorf("Filter = %q, want %q", cursor.Filter, filter.Hash())
+			}
+
+			if cursor.Sort.String() != sort.String() {
+				t.Errorf("Sort = %v, want %v", cursor.Sort, sort)
+			}
+			if got := cursor.Key(); !reflect.DeepEqual(got, tt.wantKey) {
+				t.Errorf("Key() = %#v, want %#v", got, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestParsePersonCursorInvalid(t *testing.T) {
+	encode := func(doc string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(doc))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "!!!"},
+		{name: "not JSON", cursor: encode("nope")},
+		{name: "unknown sort field", cursor: encode(`{"filter":"f","sort":"password,id","key":{"password":"x","id":1}}`)},
+		{name: "missing key member", cursor: encode(`{"filter":"f","sort":"age,id","key":{"id":1}}`)},
+		{name: "extra key member", cursor: encode(`{"filter":"f","sort":"id","key":{"id":1,"age":2}}`)},
+		{name: "wrong key type", cursor: encode(`{"filter":"f","sort":"id","key":{"id":"1"}}`)},
+		{name: "missing filter", cursor: encode(`{"sort":"id","key":{"id":1}}`)},
+		{name: "empty document", cursor: encode(`{}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParsePersonCursor(tt.cursor); !errors.Is(err, ErrValidation) {
+				t.Errorf("ParsePersonCursor() error = %v, want a validation error", err)
+			}
+		})
+	}
+}
+
+func TestPersonFilterHash(t *testing.T) {
+	age := 30
+	otherAge := 31
+	base := PersonFilter{Surname: "Smith", AgeGTE: &age, Nationalities: []string{"RU", "UA"}}
+
+	tests := []struct {
+		name     string
+		filter   PersonFilter
+		wantSame bool
+	}{
+		{name: "other age", filter: PersonFilter{Surname: "Smith", AgeGTE: &otherAge, Nationalities: []string{"RU", "UA"}}, wantSame: false},
+		{name: "equal filter", filter: PersonFilter{Surname: "Smith", AgeGTE: &age, Nationalities: []string{"RU", "UA"}}, wantSame: true},
+		{name: "reordered nationalities", filter: PersonFilter{Surname: "Smith", AgeGTE: &age, Nationalities: []string{"UA", "RU"}}, wantSame: true},
+		{name: "other field", filter: PersonFilter{Surname: "Smith", AgeLTE: &age, Nationalities: []string{"RU", "UA"}}, wantSame: false},
+		{name: "other nationalities", filter: PersonFilter{Surname: "Smith", AgeGTE: &age, Nationalities: []string{"RU"}}, wantSame: false},
+		{name: "no filter", filter: PersonFilter{}, wantSame: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := tt.filter.Hash() == base.Hash(); same != tt.wantSame {
+				t.Errorf("Hash() equal = %v, want %v", same, tt.wantSame)
+			}
+		})
+	}
+
+	filter := PersonFilter{Nationalities: []string{"UA", "RU"}}
+	filter.Hash()
+	if filter.Nationalities[0] != "UA" {
+		t.Errorf("Hash() reordered the nationalities of the filter: %v", filter.Nationalities)
+	}
+}
@@ -0,0 +1,39 @@
+package entity
+
+// AgePrediction is the age predicted for a name and the size of the sample it's based on.
+type AgePrediction struct {
+	// Age is nil if the provider has no data for the name.
+	Age   *int
+	Count int
+}
+
+// GenderPrediction is the gender predicted for a name and how certain the provider is of it.
+type GenderPrediction struct {
+	// Gender is nil if the provider has no data for the name.
+	Gender      *string
+	Probability float64
+}
+
+// NationalityProbability is a candidate nationality of a person.
+type NationalityProbability struct {
+	CountryID   string  `json:"country_id"`
+	Probability float64 `json:"probability"`
+}
+
+// Enrichment holds the attributes predicted from a person's name.
+type Enrichment struct {
+	Age               *int    `json:"age"`
+	AgeCount          int     `json:"age_count"`
+	Gender            *string `json:"gender"`
+	GenderProbability float64 `json:"gender_probability"`
+	Nationality       *string `json:"nationality"`
+	// Nationalities are ranked from the most to the least likely.
+	Nationalities []NationalityProbability `json:"nationalities"`
+}
+
+// CacheStats reports how enrichment requests were served.
+type CacheStats struct {
+	MemoryHits   int64 `json:"memory_hits"`
+	DatabaseHits int64 `json:"database_hits"`
+	Misses       int64 `json:"misses"`
+}
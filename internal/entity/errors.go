@@ -0,0 +1,110 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Error kinds. Every error returned by the repositories and services wraps
+// one of them, so the transport layer can pick a response without knowing
+// where the error came from.
+var (
+	ErrNotFound            = errors.New("not found")
+	ErrConflict            = errors.New("conflict")
+	ErrValidation          = errors.New("validation failed")
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+	ErrPreconditionFailed  = errors.New("precondition failed")
+	ErrInternal            = errors.New("internal error")
+)
+
+var (
+	// ErrPersonNotFound means there is no person with the requested ID.
+	ErrPersonNotFound = newKindError(ErrNotFound, "person not found")
+	// ErrPersonExists means a person with the same full name is already stored.
+	ErrPersonExists = newKindError(ErrConflict, "person already exists")
+	// ErrPersonModified means the person doesn't have the version the request expected.
+	ErrPersonModified = newKindError(ErrPreconditionFailed, "person was modified by another request")
+	// ErrIdempotencyKeyInUse means a request with the same Idempotency-Key is still being processed.
+	ErrIdempotencyKeyInUse = newKindError(ErrConflict, "a request with this Idempotency-Key is still being processed")
+	// ErrIdempotencyKeyReused means the Idempotency-Key was already used for a different request.
+	ErrIdempotencyKeyReused = newKindError(ErrValidation, "the Idempotency-Key was already used for a different request")
+	// ErrProviderUnavailable means an enrichment provider can't be reached,
+	// is rate limiting us or its circuit breaker is open.
+	ErrProviderUnavailable = newKindError(ErrUpstreamUnavailable, "enrichment provider unavailable")
+	// ErrProviderBadResponse means an enrichment provider answered with an
+	// error or a response that couldn't be decoded.
+	ErrProviderBadResponse = newKindError(ErrUpstreamUnavailable, "enrichment provider returned a bad response")
+)
+
+// kindError is a specific error of a more general kind.
+type kindError struct {
+	kind error
+	msg  string
+}
+
+func newKindError(kind error, msg string) error {
+	return &kindError{
+		kind: kind,
+		msg:  msg,
+	}
+}
+
+func (e *kindError) Error() string {
+	return e.msg
+}
+
+func (e *kindError) Unwrap() error {
+	return e.kind
+}
+
+// DuplicatePersonError is an ErrPersonExists that knows the ID of the stored person.
+type DuplicatePersonError struct {
+	ExistingID int
+}
+
+func (e *DuplicatePersonError) Error() string {
+	return fmt.Sprintf("%v with id %d", ErrPersonExists, e.ExistingID)
+}
+
+func (e *DuplicatePersonError) Unwrap() error {
+	return ErrPersonExists
+}
+
+// FieldError describes why a single field is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every invalid field of a request. It is of kind ErrValidation.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Add records that field is invalid.
+func (e *ValidationError) Add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// Err returns the ValidationError if any field was added, nil otherwise.
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Field+": "+field.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// PersonFilter selects the people returned by a search. Empty fields match any person.
+type PersonFilter struct {
+	Name       string
+	Surname    string
+	Patronymic string
+	// NamePrefix matches the names starting with it.
+	NamePrefix string
+	// SurnameILike matches the surnames containing it, ignoring case.
+	SurnameILike string
+	Age          *int
+	AgeGTE       *int
+	AgeLTE       *int
+	Gender       string
+	GenderNot    string
+	// Nationalities matches the people of any of the listed nationalities.
+	Nationalities []string
+}
+
+// Validate normalizes the filter and returns a ValidationError listing every
+// invalid field, named after its query parameter.
+func (f *PersonFilter) Validate() error {
+	f.Name = strings.TrimSpace(f.Name)
+	f.Surname = strings.TrimSpace(f.Surname)
+	f.Patronymic = strings.TrimSpace(f.Patronymic)
+	f.NamePrefix = strings.TrimSpace(f.NamePrefix)
+	f.SurnameILike = strings.TrimSpace(f.SurnameILike)
+	f.Gender = strings.ToLower(strings.TrimSpace(f.Gender))
+	f.GenderNot = strings.ToLower(strings.TrimSpace(f.GenderNot))
+	for i, nationality := range f.Nationalities {
+		f.Nationalities[i] = strings.ToUpper(strings.TrimSpace(nationality))
+	}
+
+	verr := &ValidationError{}
+	if utf8.RuneCountInString(f.NamePrefix) > MaxNameLength {
+		verr.Add("name_prefix", "must be at most 255 characters long")
+	}
+	if utf8.RuneCountInString(f.SurnameILike) > MaxNameLength {
+		verr.Add("surname_ilike", "must be at most 255 characters long")
+	}
+	validateAgeRange(verr, "age", f.Age)
+	validateAgeRange(verr, "age_gte", f.AgeGTE)
+	validateAgeRange(verr, "age_lte", f.AgeLTE)
+	if f.AgeGTE != nil && f.AgeLTE != nil && *f.AgeGTE > *f.AgeLTE {
+		verr.Add("age_lte", "must not be less than age_gte")
+	}
+	if f.Gender != "" {
+		validateGender(verr, &f.Gender)
+	}
+	if f.GenderNot != "" && f.GenderNot != GenderMale && f.GenderNot != GenderFemale {
+		verr.Add("gender!", `must be "male" or "female"`)
+	}
+	for _, nationality := range f.Nationalities {
+		if !IsCountryCode(nationality) {
+			verr.Add("nationality", fmt.Sprintf("%q is not an ISO 3166-1 alpha-2 country code", nationality))
+		}
+	}
+
+	return verr.Err()
+}
+
+// Hash identifies the filter, so a cursor can tell whether it is used with
+// the search it was issued for. The filter must be validated first.
+func (f PersonFilter) Hash() string {
+	// The order of the nationalities doesn't change the matches.
+	f.Nationalities = slices.Clone(f.Nationalities)
+	slices.Sort(f.Nationalities)
+
+	data, _ := json.Marshal(f)
+	sum := sha256.Sum256(data)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
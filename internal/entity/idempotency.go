@@ -0,0 +1,17 @@
+package entity
+
+// IdempotentResponse is the response stored for an Idempotency-Key and
+// replayed when the request is repeated.
+type IdempotentResponse struct {
+	StatusCode int
+	Header     map[string]string
+	Body       []byte
+}
+
+// IdempotencyRecord is what is stored under an Idempotency-Key.
+type IdempotencyRecord struct {
+	// Fingerprint identifies the request the key was first used with.
+	Fingerprint string
+	// Response is nil while the first request is still being processed.
+	Response *IdempotentResponse
+}
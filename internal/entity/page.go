@@ -0,0 +1,21 @@
+package entity
+
+// PeoplePage is a page of the people matching a search.
+type PeoplePage struct {
+	Items []*EnrichedPerson `json:"items"`
+	// Total is the number of people matching the search on all the pages.
+	Total int `json:"total"`
+	// Page is only set for numbered pages, cursor pages have no number.
+	Page    uint64 `json:"page,omitempty"`
+	PerPage uint64 `json:"per_page"`
+	// NextCursor is empty on the last page.
+	NextCursor string    `json:"next_cursor,omitempty"`
+	Links      PageLinks `json:"links"`
+}
+
+// PageLinks are the URLs of a page and of its neighbours.
+type PageLinks struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
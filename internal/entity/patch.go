@@ -0,0 +1,138 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+)
+
+// MergePatchContentType is the media type of a JSON Merge Patch.
+const MergePatchContentType = "application/merge-patch+json"
+
+// _patchableFields are the members of a person a client may change with a patch.
+var _patchableFields = map[string]bool{
+	"name":         true,
+	"surname":      true,
+	"patronymic":   true,
+	"age":          true,
+	"gender":       true,
+	"nationality":  true,
+	"country_hint": true,
+	"locale":       true,
+}
+
+// PersonPatch is a JSON Merge Patch (RFC 7396) of a person: members set to
+// null are cleared, absent members are left as they are.
+type PersonPatch map[string]json.RawMessage
+
+// ParsePersonPatch decodes a merge patch. A patch that isn't a JSON object,
+// like null, would replace the whole person, so it is rejected.
+func ParsePersonPatch(data []byte) (PersonPatch, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return nil, errPatchNotObject()
+	}
+
+	patch := PersonPatch{}
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return nil, err
+	}
+
+	return patch, nil
+}
+
+func errPatchNotObject() error {
+	verr := &ValidationError{}
+	verr.Add("patch", "must be a JSON object")
+	return verr
+}
+
+func (p PersonPatch) Bind(r *http.Request) error {
+	if p == nil {
+		return errPatchNotObject()
+	}
+
+	verr := &ValidationError{}
+	for field := range p {
+		if !_patchableFields[field] {
+			verr.Add(field, "can't be changed")
+		}
+	}
+	if err := verr.Err(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Has reports whether the patch sets field, to a value or to null.
+func (p PersonPatch) Has(field string) bool {
+	_, ok := p[field]
+	return ok
+}
+
+// Apply returns a copy of person with the patch applied. Unless the patch
+// sets the locale, the names are capitalized for the locale of the request.
+// The patched person is validated like a full replacement would be.
+func (p PersonPatch) Apply(person *EnrichedPerson, locale string) (*EnrichedPerson, error) {
+	doc, err := json.Marshal(person)
+	if err != nil {
+		return nil, err
+	}
+
+	members := map[string]json.RawMessage{}
+	if err := json.Unmarshal(doc, &members); err != nil {
+		return nil, err
+	}
+	for field, value := range p {
+		if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			delete(members, field)
+		} else {
+			members[field] = value
+		}
+	}
+
+	doc, err = json.Marshal(members)
+	if err != nil {
+		return nil, err
+	}
+
+	patched := &EnrichedPerson{}
+	if err := json.Unmarshal(doc, patched); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			verr := &ValidationError{}
+			verr.Add(typeErr.Field, "has an invalid type, expected "+jsonType(typeErr.Type.Kind()))
+			return nil, verr
+		}
+		return nil, err
+	}
+
+	if !p.Has("locale") {
+		patched.Locale = locale
+	}
+
+	patched.clean()
+	if err := patched.validate(); err != nil {
+		return nil, err
+	}
+
+	return patched, nil
+}
+
+// jsonType names the JSON type a value of kind decodes from.
+func jsonType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	default:
+		return "number"
+	}
+}
@@ -0,0 +1,173 @@
+package entity
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParsePersonPatch(t *testing.T) {
+	tests := []struct {
+		body    string
+		wantLen int
+		wantErr error
+	}{
+		{body: `{"age": 31}`, wantLen: 1},
+		{body: ` {}`, wantLen: 0},
+		{body: `null`, wantErr: ErrValidation},
+		{body: `[{"age": 31}]`, wantErr: ErrValidation},
+		{body: `"age"`, wantErr: ErrValidation},
+		{body: `31`, wantErr: ErrValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			patch, err := ParsePersonPatch([]byte(tt.body))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ParsePersonPatch(%s) error = %v, want %v", tt.body, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePersonPatch(%s) error = %v", tt.body, err)
+			}
+			if len(patch) != tt.wantLen {
+				t.Errorf("ParsePersonPatch(%s) has %d members, want %d", tt.body, len(patch), tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestPersonPatchBind(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   PersonPatch
+		wantErr bool
+	}{
+		{name: "nil patch", patch: nil, wantErr: true},
+		{name: "patchable fields", patch: mustParsePatch(t, `{"name": "Ivan", "age": null}`)},
+		{name: "server-managed field", patch: mustParsePatch(t, `{"enrichment_status": "done"}`), wantErr: true},
+		{name: "unknown field", patch: mustParsePatch(t, `{"password": "x"}`), wantErr: true},
+		{name: "patched locale", patch: mustParsePatch(t, `{"locale": "nl"}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("PATCH", "/v1/people/1", nil)
+			r.Header.Set("Accept-Language", "en")
+			members := len(tt.patch)
+
+			err := tt.patch.Bind(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Bind() error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(tt.patch) != members {
+				t.Errorf("Bind() changed the patch to %v", tt.patch)
+			}
+		})
+	}
+}
+
+func TestPersonPatchApply(t *testing.T) {
+	age := 30
+	gender := GenderMale
+	person := func() *EnrichedPerson {
+		return &EnrichedPerson{ID: 1, Name: "Ivan", Surname: "Petrov", Patronymic: "Ivanovich", Age: &age, Gender: &gender}
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		locale  string
+		check   func(t *testing.T, p *EnrichedPerson)
+		wantErr error
+	}{
+		{
+			name: "absent members are kept",
+			body: `{"age": 31}`,
+			check: func(t *testing.T, p *EnrichedPerson) {
+				if *p.Age != 31 || p.Name != "Ivan" || p.Patronymic != "Ivanovich" || *p.Gender != GenderMale {
+					t.Errorf("Apply() = %+v", p)
+				}
+			},
+		},
+		{
+			name: "null clears",
+			body: `{"patronymic": null, "gender": null}`,
+			check: func(t *testing.T, p *EnrichedPerson) {
+				if p.Patronymic != "" || p.Gender != nil {
+					t.Errorf("Apply() = %+v, want patronymic and gender cleared", p)
+				}
+			},
+		},
+		{
+			name: "values are cleaned",
+			body: `{"gender": " FEMALE ", "nationality": "ru"}`,
+			check: func(t *testing.T, p *EnrichedPerson) {
+				if *p.Gender != GenderFemale || *p.Nationality != "RU" {
+					t.Errorf("Apply() = %+v", p)
+				}
+			},
+		},
+		{
+			name:   "the request locale is the locale",
+			body:   `{"name": "Jan"}`,
+			locale: "nl",
+			check: func(t *testing.T, p *EnrichedPerson) {
+				if p.Locale != "nl" {
+					t.Errorf("Locale = %q, want nl", p.Locale)
+				}
+			},
+		},
+		{
+			name:   "patched locale wins over the request locale",
+			body:   `{"locale": "tr"}`,
+			locale: "nl",
+			check: func(t *testing.T, p *EnrichedPerson) {
+				if p.Locale != "tr" {
+					t.Errorf("Locale = %q, want tr", p.Locale)
+				}
+			},
+		},
+		{name: "required name cleared", body: `{"name": null}`, wantErr: ErrValidation},
+		{name: "invalid type", body: `{"age": "old"}`, wantErr: ErrValidation},
+		{name: "invalid value", body: `{"age": 200}`, wantErr: ErrValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch := mustParsePatch(t, tt.body)
+			if err := patch.Bind(httptest.NewRequest("PATCH", "/v1/people/1", nil)); err != nil {
+				t.Fatalf("Bind() error = %v", err)
+			}
+
+			previous := person()
+			patched, err := patch.Apply(previous, tt.locale)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Apply() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if *previous.Age != 30 || previous.Name != "Ivan" {
+				t.Errorf("Apply() changed the previous person: %+v", previous)
+			}
+			tt.check(t, patched)
+		})
+	}
+}
+
+func mustParsePatch(t *testing.T, body string) PersonPatch {
+	t.Helper()
+
+	patch, err := ParsePersonPatch([]byte(body))
+	if err != nil {
+		t.Fatalf("ParsePersonPatch(%s) error = %v", body, err)
+	}
+
+	return patch
+}
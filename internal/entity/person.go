@@ -1,58 +1,171 @@
 package entity
 
 import (
-	"errors"
+	"fmt"
 	"net/http"
+	"slices"
 	"strings"
-
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 )
 
 type PersonInput struct {
-	Name       string `json:"name"`
-	Surname    string `json:"surname"`
-	Patronymic string `json:"patronymic,omitempty"`
+	Name       string `json:"name" maxLength:"255"`
+	Surname    string `json:"surname" maxLength:"255"`
+	Patronymic string `json:"patronymic,omitempty" maxLength:"255"`
+	// CountryHint is an optional ISO 3166-1 alpha-2 code used to localize the age and gender predictions.
+	CountryHint string `json:"country_hint,omitempty"`
+	// Locale is a BCP 47 language tag used to capitalize the names. It defaults to the Accept-Language header.
+	Locale string `json:"locale,omitempty"`
 }
 
 func (p *PersonInput) Bind(r *http.Request) error {
-	if p.Name == "" {
-		return errors.New("missing required name fields")
-	}
-
-	if p.Surname == "" {
-		return errors.New("missing required surname fields")
-	}
-
 	p.Name = strings.TrimSpace(p.Name)
 	p.Surname = strings.TrimSpace(p.Surname)
 	p.Patronymic = strings.TrimSpace(p.Patronymic)
+	p.CountryHint = strings.ToUpper(strings.TrimSpace(p.CountryHint))
 
-	p.Name = cases.Title(language.English).String(p.Name)
-	p.Surname = cases.Title(language.English).String(p.Surname)
-	p.Patronymic = cases.Title(language.English).String(p.Patronymic)
+	verr := &ValidationError{}
+	validateName(verr, "name", p.Name, true)
+	validateName(verr, "surname", p.Surname, true)
+	validateName(verr, "patronymic", p.Patronymic, false)
+	validateCountryCode(verr, "country_hint", p.CountryHint)
+	if p.Locale == "" {
+		p.Locale = RequestLocale(r)
+	}
+	p.Locale = validateLocale(verr, p.Locale)
+	if err := verr.Err(); err != nil {
+		return err
+	}
 
 	return nil
 }
 
+// Enriched attributes, as listed in EnrichedPerson.LowConfidence.
+const (
+	AttributeAge         = "age"
+	AttributeGender      = "gender"
+	AttributeNationality = "nationality"
+)
+
+// Enrichment statuses of a stored person.
+const (
+	EnrichmentPending = "pending"
+	EnrichmentDone    = "done"
+	EnrichmentFailed  = "failed"
+)
+
 type EnrichedPerson struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	Surname     string `json:"surname"`
-	Patronymic  string `json:"patronymic,omitempty"`
-	Age         int    `json:"age"`
-	Gender      string `json:"gender"`
-	Nationality string `json:"nationality"`
+	ID         int    `json:"id"`
+	Name       string `json:"name" maxLength:"255"`
+	Surname    string `json:"surname" maxLength:"255"`
+	Patronymic string `json:"patronymic,omitempty" maxLength:"255"`
+	// Age, Gender and Nationality are nil when they are unknown or the
+	// provider wasn't confident enough about them.
+	Age               *int    `json:"age" minimum:"0" maximum:"150"`
+	AgeCount          int     `json:"age_count"`
+	Gender            *string `json:"gender" enums:"male,female"`
+	GenderProbability float64 `json:"gender_probability" minimum:"0" maximum:"1"`
+	Nationality       *string `json:"nationality"`
+	// Nationalities are ranked from the most to the least likely.
+	Nationalities []NationalityProbability `json:"nationalities"`
+	// LowConfidence lists the attributes left unknown because the provider's
+	// confidence was below the configured threshold.
+	LowConfidence    []string `json:"low_confidence,omitempty"`
+	CountryHint      string   `json:"country_hint,omitempty"`
+	EnrichmentStatus string   `json:"enrichment_status"`
+	// Version is incremented on every change of the person and sent as its ETag.
+	Version int `json:"-"`
+	// Locale is only read from requests to capitalize the names; it isn't stored.
+	Locale string `json:"locale,omitempty"`
+}
+
+// Enrichment returns the predicted attributes of the person.
+func (p *EnrichedPerson) Enrichment() *Enrichment {
+	return &Enrichment{
+		Age:               p.Age,
+		AgeCount:          p.AgeCount,
+		Gender:            p.Gender,
+		GenderProbability: p.GenderProbability,
+		Nationality:       p.Nationality,
+		Nationalities:     slices.Clone(p.Nationalities),
+	}
+}
+
+// SetEnrichment replaces the predicted attributes of the person.
+func (p *EnrichedPerson) SetEnrichment(e *Enrichment) {
+	p.Age = e.Age
+	p.AgeCount = e.AgeCount
+	p.Gender = e.Gender
+	p.GenderProbability = e.GenderProbability
+	p.Nationality = e.Nationality
+	p.Nationalities = slices.Clone(e.Nationalities)
+}
+
+// SetByClient marks an attribute as set by a client rather than predicted, so
+// it isn't listed as a low-confidence prediction anymore.
+func (p *EnrichedPerson) SetByClient(attribute string) {
+	p.LowConfidence = slices.DeleteFunc(p.LowConfidence, func(a string) bool {
+		return a == attribute
+	})
 }
 
 func (p *EnrichedPerson) Bind(r *http.Request) error {
+	p.clean()
+	if p.Locale == "" {
+		p.Locale = RequestLocale(r)
+	}
+
+	// The enrichment status and the low-confidence attributes are managed by
+	// the service; a replacement sets every attribute itself.
+	p.EnrichmentStatus = ""
+	p.LowConfidence = nil
+
+	return p.validate()
+}
+
+// clean trims the fields of the person and brings the codes to their canonical case.
+func (p *EnrichedPerson) clean() {
 	p.Name = strings.TrimSpace(p.Name)
 	p.Surname = strings.TrimSpace(p.Surname)
 	p.Patronymic = strings.TrimSpace(p.Patronymic)
+	p.CountryHint = strings.ToUpper(strings.TrimSpace(p.CountryHint))
 
-	p.Name = cases.Title(language.English).String(p.Name)
-	p.Surname = cases.Title(language.English).String(p.Surname)
-	p.Patronymic = cases.Title(language.English).String(p.Patronymic)
+	if p.Gender != nil {
+		gender := strings.ToLower(strings.TrimSpace(*p.Gender))
+		p.Gender = &gender
+	}
+	if p.Nationality != nil {
+		nationality := strings.ToUpper(strings.TrimSpace(*p.Nationality))
+		p.Nationality = &nationality
+	}
+}
 
-	return nil
+// validate returns a ValidationError listing every invalid field of the person.
+func (p *EnrichedPerson) validate() error {
+	verr := &ValidationError{}
+	validateName(verr, "name", p.Name, true)
+	validateName(verr, "surname", p.Surname, true)
+	validateName(verr, "patronymic", p.Patronymic, false)
+	validateCountryCode(verr, "country_hint", p.CountryHint)
+	p.Locale = validateLocale(verr, p.Locale)
+	validateAge(verr, p.Age)
+	if p.AgeCount < 0 {
+		verr.Add("age_count", "must not be negative")
+	}
+	validateGender(verr, p.Gender)
+	validateProbability(verr, "gender_probability", p.GenderProbability)
+	if p.Nationality != nil {
+		validateCountryCode(verr, "nationality", *p.Nationality)
+		if *p.Nationality == "" {
+			verr.Add("nationality", "must be an ISO 3166-1 alpha-2 country code")
+		}
+	}
+	for i, n := range p.Nationalities {
+		field := fmt.Sprintf("nationalities[%d]", i)
+		if !IsCountryCode(n.CountryID) {
+			verr.Add(field+".country_id", "must be an ISO 3166-1 alpha-2 country code")
+		}
+		validateProbability(verr, field+".probability", n.Probability)
+	}
+
+	return verr.Err()
 }
@@ -0,0 +1,191 @@
+package entity
+
+import (
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// validationFields returns the invalid fields reported by err, failing the
+// test if err isn't a single ValidationError.
+func validationFields(t *testing.T, err error) []string {
+	t.Helper()
+
+	if err == nil {
+		return nil
+	}
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) || !errors.Is(err, ErrValidation) {
+		t.Fatalf("error = %v, want a *ValidationError", err)
+	}
+
+	fields := make([]string, 0, len(verr.Fields))
+	for _, f := range verr.Fields {
+		fields = append(fields, f.Field)
+	}
+
+	return fields
+}
+
+func TestPersonInputBind(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      PersonInput
+		wantFields []string
+	}{
+		{
+			name:  "valid",
+			input: PersonInput{Name: " Anne-Marie ", Surname: "O'Neil", Patronymic: "Mary Ann", CountryHint: "ie"},
+		},
+		{
+			name:  "combining mark after a letter",
+			input: PersonInput{Name: "Zoe\u0308", Surname: "Bronte\u0308"},
+		},
+		{
+			name:       "digits",
+			input:      PersonInput{Name: "Dmitriy2", Surname: "Ushakov"},
+			wantFields: []string{"name"},
+		},
+		{
+			name:       "emoji",
+			input:      PersonInput{Name: "Dmitriy", Surname: "Ushakov😀"},
+			wantFields: []string{"surname"},
+		},
+		{
+			name:       "too long",
+			input:      PersonInput{Name: strings.Repeat("a", MaxNameLength+1), Surname: "Ushakov"},
+			wantFields: []string{"name"},
+		},
+		{
+			name:  "longest",
+			input: PersonInput{Name: strings.Repeat("a", MaxNameLength), Surname: "Ushakov"},
+		},
+		{
+			name:       "double hyphen",
+			input:      PersonInput{Name: "Anne--Marie", Surname: "Ushakov"},
+			wantFields: []string{"name"},
+		},
+		{
+			name:       "leading combining mark",
+			input:      PersonInput{Name: "\u0301Anne", Surname: "Ushakov"},
+			wantFields: []string{"name"},
+		},
+		{
+			name:       "user-assigned country",
+			input:      PersonInput{Name: "Dmitriy", Surname: "Ushakov", CountryHint: "XX"},
+			wantFields: []string{"country_hint"},
+		},
+		{
+			name:       "unknown country",
+			input:      PersonInput{Name: "Dmitriy", Surname: "Ushakov", CountryHint: "ZZ"},
+			wantFields: []string{"country_hint"},
+		},
+		{
+			name:       "every violation at once",
+			input:      PersonInput{Name: "", Surname: "Ushakov1", Patronymic: "-Vasilevich", CountryHint: "ZZ", Locale: "not a tag"},
+			wantFields: []string{"name", "surname", "patronymic", "country_hint", "locale"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := tt.input
+			err := input.Bind(httptest.NewRequest("POST", "/v1/people", nil))
+
+			if got := validationFields(t, err); !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("Bind() invalid fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestEnrichedPersonValidate(t *testing.T) {
+	valid := func() EnrichedPerson {
+		age, gender, nationality := 42, GenderMale, "RU"
+		return EnrichedPerson{
+			Name:              "Dmitriy",
+			Surname:           "Ushakov",
+			Age:               &age,
+			Gender:            &gender,
+			GenderProbability: 0.9,
+			Nationality:       &nationality,
+			Nationalities:     []NationalityProbability{{CountryID: "RU", Probability: 0.7}},
+		}
+	}
+	intPtr := func(i int) *int { return &i }
+	strPtr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name       string
+		modify     func(p *EnrichedPerson)
+		wantFields []string
+	}{
+		{
+			name:   "valid",
+			modify: func(p *EnrichedPerson) {},
+		},
+		{
+			name:   "unknown attributes",
+			modify: func(p *EnrichedPerson) { p.Age, p.Gender, p.Nationality, p.Nationalities = nil, nil, nil, nil },
+		},
+		{
+			name:       "age above range",
+			modify:     func(p *EnrichedPerson) { p.Age = intPtr(MaxAge + 1) },
+			wantFields: []string{"age"},
+		},
+		{
+			name:       "age below range",
+			modify:     func(p *EnrichedPerson) { p.Age = intPtr(MinAge - 1) },
+			wantFields: []string{"age"},
+		},
+		{
+			name:       "probability above range",
+			modify:     func(p *EnrichedPerson) { p.GenderProbability = 1.5 },
+			wantFields: []string{"gender_probability"},
+		},
+		{
+			name:       "nationality probability below range",
+			modify:     func(p *EnrichedPerson) { p.Nationalities[0].Probability = -0.1 },
+			wantFields: []string{"nationalities[0].probability"},
+		},
+		{
+			name:       "user-assigned nationality",
+			modify:     func(p *EnrichedPerson) { p.Nationality = strPtr("XX") },
+			wantFields: []string{"nationality"},
+		},
+		{
+			name:       "unknown nationality",
+			modify:     func(p *EnrichedPerson) { p.Nationalities[0].CountryID = "ZZ" },
+			wantFields: []string{"nationalities[0].country_id"},
+		},
+		{
+			name: "every violation at once",
+			modify: func(p *EnrichedPerson) {
+				p.Name = "Dmitriy😀"
+				p.Surname = strings.Repeat("a", MaxNameLength+1)
+				p.Patronymic = "Anne--Marie"
+				p.Age = intPtr(200)
+				p.Gender = strPtr("other")
+				p.GenderProbability = 2
+				p.Nationality = strPtr("ZZ")
+				p.Nationalities[0] = NationalityProbability{CountryID: "XX", Probability: 3}
+			},
+			wantFields: []string{
+				"name", "surname", "patronymic", "age", "gender", "gender_probability", "nationality",
+				"nationalities[0].country_id", "nationalities[0].probability",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			person := valid()
+			tt.modify(&person)
+
+			if got := validationFields(t, person.validate()); !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("validate() invalid fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+)
+
+// _sortableFields are the person fields a search may be sorted by.
+var _sortableFields = map[string]bool{
+	"id":          true,
+	"name":        true,
+	"surname":     true,
+	"patronymic":  true,
+	"age":         true,
+	"gender":      true,
+	"nationality": true,
+}
+
+// SortField orders a search by one field of a person.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// PersonSort is the order of a search, most significant field first. It
+// always ends with id, so people with equal sort keys keep a stable order.
+type PersonSort []SortField
+
+// ParsePersonSort parses a comma-separated list of fields, each of them
+// prefixed with "-" for the descending order, e.g. "-age,surname".
+func ParsePersonSort(s string) (PersonSort, error) {
+	sort := PersonSort{}
+	seen := map[string]bool{}
+
+	verr := &ValidationError{}
+	if strings.TrimSpace(s) != "" {
+		for _, field := range strings.Split(s, ",") {
+			field = strings.TrimSpace(field)
+			sortField := SortField{Field: strings.TrimPrefix(field, "-"), Desc: strings.HasPrefix(field, "-")}
+
+			switch {
+			case !_sortableFields[sortField.Field]:
+				verr.Add("sort", fmt.Sprintf("%q is not a sortable field", field))
+			case seen[sortField.Field]:
+				verr.Add("sort", fmt.Sprintf("%q is listed more than once", sortField.Field))
+			default:
+				seen[sortField.Field] = true
+				sort = append(sort, sortField)
+			}
+		}
+	}
+	if err := verr.Err(); err != nil {
+		return nil, err
+	}
+
+	if !seen["id"] {
+		sort = append(sort, SortField{Field: "id"})
+	}
+
+	return sort, nil
+}
+
+// String returns the sort in the form ParsePersonSort accepts.
+func (s PersonSort) String() string {
+	fields := make([]string, 0, len(s))
+	for _, field := range s {
+		if field.Desc {
+			fields = append(fields, "-"+field.Field)
+		} else {
+			fields = append(fields, field.Field)
+		}
+	}
+
+	return strings.Join(fields, ",")
+}
@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParsePersonSort(t *testing.T) {
+	tests := []struct {
+		sort    string
+		want    PersonSort
+		wantErr bool
+	}{
+		{sort: "", want: PersonSort{{Field: "id"}}},
+		{sort: "  ", want: PersonSort{{Field: "id"}}},
+		{sort: "-age,surname", want: PersonSort{{Field: "age", Desc: true}, {Field: "surname"}, {Field: "id"}}},
+		{sort: " name , -nationality ", want: PersonSort{{Field: "name"}, {Field: "nationality", Desc: true}, {Field: "id"}}},
+		{sort: "age,-id", want: PersonSort{{Field: "age"}, {Field: "id", Desc: true}}},
+		{sort: "password", wantErr: true},
+		{sort: "age,-age", wantErr: true},
+		{sort: "age,", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			got, err := ParsePersonSort(tt.sort)
+			if tt.wantErr {
+				if !errors.Is(err, ErrValidation) {
+					t.Fatalf("ParsePersonSort(%q) error = %v, want a validation error", tt.sort, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePersonSort(%q) error = %v", tt.sort, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePersonSort(%q) = %v, want %v", tt.sort, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPersonSortString(t *testing.T) {
+	for _, sort := range []string{"id", "-age,surname,id", "name,-id"} {
+		parsed, err := ParsePersonSort(sort)
+		if err != nil {
+			t.Fatalf("ParsePersonSort(%q) error = %v", sort, err)
+		}
+		if got := parsed.String(); got != sort {
+			t.Errorf("ParsePersonSort(%q).String() = %q", sort, got)
+		}
+	}
+}
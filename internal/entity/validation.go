@@ -0,0 +1,143 @@
+package entity
+
+import (
+	"net/http"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+)
+
+// Limits of the person attributes, matching the people table.
+const (
+	MaxNameLength = 255
+	MinAge        = 0
+	MaxAge        = 150
+)
+
+// Genders accepted for a person.
+const (
+	GenderMale   = "male"
+	GenderFemale = "female"
+)
+
+// validateName checks that value is a name of letters, optionally compounded
+// with single hyphens, apostrophes or spaces, like "Anne-Marie" or "O'Neil".
+func validateName(verr *ValidationError, field, value string, required bool) {
+	if value == "" {
+		if required {
+			verr.Add(field, "is required")
+		}
+		return
+	}
+
+	if utf8.RuneCountInString(value) > MaxNameLength {
+		verr.Add(field, "must be at most 255 characters long")
+		return
+	}
+
+	var prev rune
+	for i, r := range value {
+		switch {
+		case unicode.IsLetter(r):
+		case unicode.Is(unicode.M, r):
+			// Combining marks must follow a letter or another mark.
+			if i == 0 || isNameSeparator(prev) {
+				verr.Add(field, "must start with a letter")
+				return
+			}
+		case isNameSeparator(r):
+			if i == 0 {
+				verr.Add(field, "must start with a letter")
+				return
+			}
+			if isNameSeparator(prev) {
+				verr.Add(field, "must consist of letters separated by single hyphens, apostrophes or spaces")
+				return
+			}
+		default:
+			verr.Add(field, "must contain only letters, hyphens, apostrophes and spaces")
+			return
+		}
+		prev = r
+	}
+
+	if isNameSeparator(prev) {
+		verr.Add(field, "must end with a letter")
+	}
+}
+
+func isNameSeparator(r rune) bool {
+	switch r {
+	case '-', '\'', '’', ' ':
+		return true
+	}
+	return false
+}
+
+// IsCountryCode reports whether code is an assigned ISO 3166-1 alpha-2 country code.
+func IsCountryCode(code string) bool {
+	if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+		return false
+	}
+
+	region, err := language.ParseRegion(code)
+	if err != nil {
+		return false
+	}
+
+	return region.IsCountry() && region.ISO3() != "ZZZ"
+}
+
+func validateCountryCode(verr *ValidationError, field, value string) {
+	if value != "" && !IsCountryCode(value) {
+		verr.Add(field, "must be an ISO 3166-1 alpha-2 country code")
+	}
+}
+
+func validateAge(verr *ValidationError, age *int) {
+	validateAgeRange(verr, "age", age)
+}
+
+func validateAgeRange(verr *ValidationError, field string, age *int) {
+	if age != nil && (*age < MinAge || *age > MaxAge) {
+		verr.Add(field, "must be between 0 and 150")
+	}
+}
+
+func validateGender(verr *ValidationError, gender *string) {
+	if gender != nil && *gender != GenderMale && *gender != GenderFemale {
+		verr.Add("gender", `must be "male" or "female"`)
+	}
+}
+
+func validateProbability(verr *ValidationError, field string, probability float64) {
+	if probability < 0 || probability > 1 {
+		verr.Add(field, "must be between 0 and 1")
+	}
+}
+
+// RequestLocale returns the preferred language of the Accept-Language header, if any.
+func RequestLocale(r *http.Request) string {
+	tags, _, err := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	if err != nil || len(tags) == 0 || tags[0] == language.Und {
+		return ""
+	}
+
+	return tags[0].String()
+}
+
+// validateLocale checks that locale is a BCP 47 language tag and returns it in canonical form.
+func validateLocale(verr *ValidationError, locale string) string {
+	if locale == "" {
+		return ""
+	}
+
+	tag, err := language.Parse(locale)
+	if err != nil {
+		verr.Add("locale", "must be a BCP 47 language tag")
+		return locale
+	}
+
+	return tag.String()
+}
@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ETag returns the entity tag of a person's version.
+func ETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// VersionMatch is the If-Match condition of a request: the versions the
+// person must have for the request to go on. A nil VersionMatch matches any version.
+type VersionMatch []int
+
+// ParseIfMatch parses the If-Match header. Weak entity tags and tags that
+// weren't issued by ETag never match.
+func ParseIfMatch(header string) VersionMatch {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return nil
+	}
+
+	match := VersionMatch{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) || len(tag) < 2 {
+			continue
+		}
+		if version, err := strconv.Atoi(tag[1 : len(tag)-1]); err == nil {
+			match = append(match, version)
+		}
+	}
+
+	return match
+}
+
+// Matches reports whether a person of the given version satisfies the condition.
+func (m VersionMatch) Matches(version int) bool {
+	return m == nil || slices.Contains(m, version)
+}
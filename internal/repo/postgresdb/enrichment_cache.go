@@ -0,0 +1,85 @@
+package postgresdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/realPointer/EnrichInfo/internal/entity"
+	"github.com/realPointer/EnrichInfo/pkg/postgres"
+)
+
+type EnrichmentCacheRepo struct {
+	*postgres.Postgres
+}
+
+func NewEnrichmentCacheRepo(pg *postgres.Postgres) *EnrichmentCacheRepo {
+	return &EnrichmentCacheRepo{
+		Postgres: pg,
+	}
+}
+
+// GetEnrichment returns the cached enrichment for the name and country hint,
+// or nil if there is no entry or it has expired.
+func (r *EnrichmentCacheRepo) GetEnrichment(ctx context.Context, name, countryHint string) (*entity.Enrichment, error) {
+	sql, args, _ := r.Builder.
+		Select("data").
+		From("enrichment_cache").
+		Where("name = ? AND country_hint = ? AND expires_at > now()", name, countryHint).
+		ToSql()
+
+	var data []byte
+	err := r.Pool.QueryRow(ctx, sql, args...).Scan(&data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("EnrichmentCacheRepo - GetEnrichment - row.Scan: %w: %v", entity.ErrInternal, err)
+	}
+
+	enrichment := &entity.Enrichment{}
+	if err := json.Unmarshal(data, enrichment); err != nil {
+		return nil, fmt.Errorf("EnrichmentCacheRepo - GetEnrichment - json.Unmarshal: %w: %v", entity.ErrInternal, err)
+	}
+
+	return enrichment, nil
+}
+
+// DeleteExpiredEnrichments deletes the expired entries and returns how many there were.
+func (r *EnrichmentCacheRepo) DeleteExpiredEnrichments(ctx context.Context) (int64, error) {
+	sql, args, _ := r.Builder.
+		Delete("enrichment_cache").
+		Where("expires_at <= now()").
+		ToSql()
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("EnrichmentCacheRepo - DeleteExpiredEnrichments - r.Pool.Exec: %w: %v", entity.ErrInternal, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (r *EnrichmentCacheRepo) SaveEnrichment(ctx context.Context, name, countryHint string, enrichment *entity.Enrichment, expiresAt time.Time) error {
+	data, err := json.Marshal(enrichment)
+	if err != nil {
+		return fmt.Errorf("EnrichmentCacheRepo - SaveEnrichment - json.Marshal: %w: %v", entity.ErrInternal, err)
+	}
+
+	sql, args, _ := r.Builder.
+		Insert("enrichment_cache").
+		Columns("name", "country_hint", "data", "expires_at").
+		Values(name, countryHint, data, expiresAt).
+		Suffix("ON CONFLICT (name, country_hint) DO UPDATE SET data = EXCLUDED.data, expires_at = EXCLUDED.expires_at").
+		ToSql()
+
+	_, err = r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("EnrichmentCacheRepo - SaveEnrichment - tx.Exec: %w: %v", entity.ErrInternal, err)
+	}
+
+	return nil
+}
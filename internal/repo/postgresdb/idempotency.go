@@ -0,0 +1,127 @@
+package postgresdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/realPointer/EnrichInfo/internal/entity"
+	"github.com/realPointer/EnrichInfo/pkg/postgres"
+)
+
+type IdempotencyRepo struct {
+	*postgres.Postgres
+}
+
+func NewIdempotencyRepo(pg *postgres.Postgres) *IdempotencyRepo {
+	return &IdempotencyRepo{
+		Postgres: pg,
+	}
+}
+
+// ReserveIdempotencyKey stores key for the request with the given fingerprint
+// until expiresAt, the end of the lease of the request in progress. It returns
+// nil if the key was free or had expired, or the record already stored under
+// the key otherwise.
+func (r *IdempotencyRepo) ReserveIdempotencyKey(ctx context.Context, key, fingerprint string, expiresAt time.Time) (*entity.IdempotencyRecord, error) {
+	sql, args, _ := r.Builder.
+		Insert("idempotency_keys").
+		Columns("key", "fingerprint", "expires_at").
+		Values(key, fingerprint, expiresAt).
+		Suffix(`ON CONFLICT (key) DO UPDATE
+			SET fingerprint = EXCLUDED.fingerprint, status_code = NULL, header = NULL, body = NULL, expires_at = EXCLUDED.expires_at
+			WHERE idempotency_keys.expires_at <= now()
+			RETURNING key`).
+		ToSql()
+
+	err := r.Pool.QueryRow(ctx, sql, args...).Scan(&key)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("IdempotencyRepo - ReserveIdempotencyKey - row.Scan: %w: %v", entity.ErrInternal, err)
+	}
+
+	// The key is taken, so return what is stored under it.
+	sql, args, _ = r.Builder.
+		Select("fingerprint", "status_code", "header", "body").
+		From("idempotency_keys").
+		Where("key = ?", key).
+		ToSql()
+
+	var (
+		record     entity.IdempotencyRecord
+		statusCode *int
+		header     map[string]string
+		body       []byte
+	)
+	err = r.Pool.QueryRow(ctx, sql, args...).Scan(&record.Fingerprint, &statusCode, &header, &body)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// The first request failed and released the key in the meantime.
+		return nil, entity.ErrIdempotencyKeyInUse
+	}
+	if err != nil {
+		return nil, fmt.Errorf("IdempotencyRepo - ReserveIdempotencyKey - row.Scan: %w: %v", entity.ErrInternal, err)
+	}
+
+	if statusCode != nil {
+		record.Response = &entity.IdempotentResponse{
+			StatusCode: *statusCode,
+			Header:     header,
+			Body:       body,
+		}
+	}
+
+	return &record, nil
+}
+
+// SaveIdempotentResponse stores the response to replay for key until expiresAt.
+func (r *IdempotencyRepo) SaveIdempotentResponse(ctx context.Context, key string, response *entity.IdempotentResponse, expiresAt time.Time) error {
+	sql, args, _ := r.Builder.
+		Update("idempotency_keys").
+		Set("status_code", response.StatusCode).
+		Set("header", response.Header).
+		Set("body", response.Body).
+		Set("expires_at", expiresAt).
+		Where("key = ?", key).
+		ToSql()
+
+	_, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("IdempotencyRepo - SaveIdempotentResponse - r.Pool.Exec: %w: %v", entity.ErrInternal, err)
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepo) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	sql, args, _ := r.Builder.
+		Delete("idempotency_keys").
+		Where("key = ?", key).
+		ToSql()
+
+	_, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("IdempotencyRepo - DeleteIdempotencyKey - r.Pool.Exec: %w: %v", entity.ErrInternal, err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys deletes the expired keys, with their stored
+// responses, and returns how many there were.
+func (r *IdempotencyRepo) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	sql, args, _ := r.Builder.
+		Delete("idempotency_keys").
+		Where("expires_at <= now()").
+		ToSql()
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("IdempotencyRepo - DeleteExpiredIdempotencyKeys - r.Pool.Exec: %w: %v", entity.ErrInternal, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -2,12 +2,29 @@ package postgresdb
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/realPointer/EnrichInfo/internal/entity"
 	"github.com/realPointer/EnrichInfo/pkg/postgres"
 )
 
+var _personColumns = []string{
+	"id", "name", "surname", "patronymic", "age", "age_count", "gender", "gender_probability", "nationality",
+	"low_confidence", "country_hint", "enrichment_status", "version",
+}
+
+const (
+	_uniqueViolation = "23505"
+	// _fullNameKey is the unique index on the case-insensitive full name of a person.
+	_fullNameKey = "people_full_name_key"
+)
+
 type PersonRepo struct {
 	*postgres.Postgres
 }
@@ -18,50 +35,128 @@ func NewPersonRepo(pg *postgres.Postgres) *PersonRepo {
 	}
 }
 
-func (r *PersonRepo) CreatePerson(ctx context.Context, person *entity.EnrichedPerson) error {
-	sql, args, _ := r.Builder.
-		Insert("people").
-		Columns("name", "surname", "patronymic", "age", "gender", "nationality").
-		Values(person.Name, person.Surname, person.Patronymic, person.Age, person.Gender, person.Nationality).
-		ToSql()
+func (r *PersonRepo) CreatePerson(ctx context.Context, person *entity.EnrichedPerson) (*entity.EnrichedPerson, error) {
+	var createdPerson *entity.EnrichedPerson
+
+	err := r.WithinTx(ctx, func(ctx context.Context) error {
+		sql, args, _ := r.Builder.
+			Insert("people").
+			Columns(
+				"name", "surname", "patronymic", "age", "age_count", "gender", "gender_probability", "nationality",
+				"low_confidence", "country_hint", "enrichment_status",
+			).
+			Values(
+				person.Name, person.Surname, person.Patronymic, person.Age, person.AgeCount, person.Gender, person.GenderProbability, person.Nationality,
+				person.LowConfidence, person.CountryHint, person.EnrichmentStatus,
+			).
+			Suffix("RETURNING " + strings.Join(_personColumns, ", ")).
+			ToSql()
 
-	_, err := r.Pool.Exec(ctx, sql, args...)
+		var err error
+		createdPerson, err = scanPerson(r.Querier(ctx).QueryRow(ctx, sql, args...))
+		if isDuplicatePerson(err) {
+			return fmt.Errorf("PersonRepo - CreatePerson - row.Scan: %w", r.duplicatePersonError(ctx, person))
+		}
+		if err != nil {
+			return fmt.Errorf("PersonRepo - CreatePerson - row.Scan: %w: %v", entity.ErrInternal, err)
+		}
+
+		err = r.insertNationalities(ctx, createdPerson.ID, person.Nationalities)
+		if err != nil {
+			return fmt.Errorf("PersonRepo - CreatePerson - r.insertNationalities: %w: %v", entity.ErrInternal, err)
+		}
+		createdPerson.Nationalities = append([]entity.NationalityProbability{}, person.Nationalities...)
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("PersonRepo - CreatePerson - tx.Exec: %v", err)
+		return nil, fmt.Errorf("PersonRepo - CreatePerson - r.WithinTx: %w", err)
 	}
 
-	return nil
+	return createdPerson, nil
 }
 
+// UpdatePerson stores the person if it still has person.Version, and sets
+// person.Version to the new version. It fails with entity.ErrPersonModified
+// if the person was changed in the meantime.
 func (r *PersonRepo) UpdatePerson(ctx context.Context, id int, updatedPerson *entity.EnrichedPerson) error {
-	sql, args, _ := r.Builder.
-		Update("people").
-		Set("name", updatedPerson.Name).
-		Set("surname", updatedPerson.Surname).
-		Set("patronymic", updatedPerson.Patronymic).
-		Set("age", updatedPerson.Age).
-		Set("gender", updatedPerson.Gender).
-		Set("nationality", updatedPerson.Nationality).
-		Where("id = ?", id).
-		ToSql()
+	var version int
+
+	err := r.WithinTx(ctx, func(ctx context.Context) error {
+		sql, args, _ := r.Builder.
+			Update("people").
+			Set("name", updatedPerson.Name).
+			Set("surname", updatedPerson.Surname).
+			Set("patronymic", updatedPerson.Patronymic).
+			Set("age", updatedPerson.Age).
+			Set("age_count", updatedPerson.AgeCount).
+			Set("gender", updatedPerson.Gender).
+			Set("gender_probability", updatedPerson.GenderProbability).
+			Set("nationality", updatedPerson.Nationality).
+			Set("low_confidence", updatedPerson.LowConfidence).
+			Set("country_hint", updatedPerson.CountryHint).
+			Set("enrichment_status", updatedPerson.EnrichmentStatus).
+			Set("version", squirrel.Expr("version + 1")).
+			Where("id = ? AND version = ?", id, updatedPerson.Version).
+			Suffix("RETURNING version").
+			ToSql()
+
+		err := r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&version)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("PersonRepo - UpdatePerson - row.Scan: %w", r.missingPersonError(ctx, id))
+		}
+		if isDuplicatePerson(err) {
+			return fmt.Errorf("PersonRepo - UpdatePerson - row.Scan: %w", r.duplicatePersonError(ctx, updatedPerson))
+		}
+		if err != nil {
+			return fmt.Errorf("PersonRepo - UpdatePerson - row.Scan: %w: %v", entity.ErrInternal, err)
+		}
 
-	_, err := r.Pool.Exec(ctx, sql, args...)
+		sql, args, _ = r.Builder.
+			Delete("person_nationalities").
+			Where("person_id = ?", id).
+			ToSql()
+
+		_, err = r.Querier(ctx).Exec(ctx, sql, args...)
+		if err != nil {
+			return fmt.Errorf("PersonRepo - UpdatePerson - tx.Exec: %w: %v", entity.ErrInternal, err)
+		}
+
+		err = r.insertNationalities(ctx, id, updatedPerson.Nationalities)
+		if err != nil {
+			return fmt.Errorf("PersonRepo - UpdatePerson - r.insertNationalities: %w: %v", entity.ErrInternal, err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("PersonRepo - UpdatePerson - tx.Exec: %v", err)
+		return fmt.Errorf("PersonRepo - UpdatePerson - r.WithinTx: %w", err)
 	}
+	updatedPerson.Version = version
 
 	return nil
 }
 
-func (r *PersonRepo) DeletePerson(ctx context.Context, id int) error {
-	sql, args, _ := r.Builder.
+// DeletePerson deletes the person if it has the given version; version 0
+// matches any version.
+func (r *PersonRepo) DeletePerson(ctx context.Context, id, version int) error {
+	builder := r.Builder.
 		Delete("people").
-		Where("id = ?", id).
-		ToSql()
+		Where("id = ?", id)
+	if version != 0 {
+		builder = builder.Where("version = ?", version)
+	}
+	sql, args, _ := builder.ToSql()
 
-	_, err := r.Pool.Exec(ctx, sql, args...)
+	tag, err := r.Querier(ctx).Exec(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("PersonRepo - DeletePerson - tx.Exec: %v", err)
+		return fmt.Errorf("PersonRepo - DeletePerson - tx.Exec: %w: %v", entity.ErrInternal, err)
+	}
+	if tag.RowsAffected() == 0 {
+		if version == 0 {
+			return fmt.Errorf("PersonRepo - DeletePerson - tx.Exec: %w", entity.ErrPersonNotFound)
+		}
+		return fmt.Errorf("PersonRepo - DeletePerson - tx.Exec: %w", r.missingPersonError(ctx, id))
 	}
 
 	return nil
@@ -69,56 +164,401 @@ func (r *PersonRepo) DeletePerson(ctx context.Context, id int) error {
 
 func (r *PersonRepo) GetPerson(ctx context.Context, id int) (*entity.EnrichedPerson, error) {
 	sql, args, _ := r.Builder.
-		Select("*").
+		Select(_personColumns...).
 		From("people").
 		Where("id = ?", id).
 		ToSql()
 
-	row := r.Pool.QueryRow(ctx, sql, args...)
-	person := &entity.EnrichedPerson{}
-	err := row.Scan(&person.ID, &person.Name, &person.Surname, &person.Patronymic, &person.Age, &person.Gender, &person.Nationality)
+	person, err := scanPerson(r.Querier(ctx).QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("PersonRepo - GetPerson - row.Scan: %w", entity.ErrPersonNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("PersonRepo - GetPerson - row.Scan: %w: %v", entity.ErrInternal, err)
+	}
+
+	err = r.loadNationalities(ctx, []*entity.EnrichedPerson{person})
 	if err != nil {
-		return nil, fmt.Errorf("PersonRepo - GetPerson - row.Scan: %v", err)
+		return nil, fmt.Errorf("PersonRepo - GetPerson - r.loadNationalities: %w: %v", entity.ErrInternal, err)
 	}
 
 	return person, nil
 }
 
-func (r *PersonRepo) SearchPeople(ctx context.Context, filters map[string]string, page, perPage uint64) ([]*entity.EnrichedPerson, error) {
-	builder := r.Builder.Select("*").From("people")
+// GetPersonForUpdate is GetPerson locking the person until the end of the
+// transaction, so it must be called within r.WithinTx.
+func (r *PersonRepo) GetPersonForUpdate(ctx context.Context, id int) (*entity.EnrichedPerson, error) {
+	sql, args, _ := r.Builder.
+		Select(_personColumns...).
+		From("people").
+		Where("id = ?", id).
+		Suffix("FOR UPDATE").
+		ToSql()
 
-	// Add filters to the query
-	for key, value := range filters {
-		if value != "" {
-			builder = builder.Where(fmt.Sprintf("%s = ?", key), value)
-		}
+	person, err := scanPerson(r.Querier(ctx).QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("PersonRepo - GetPersonForUpdate - row.Scan: %w", entity.ErrPersonNotFound)
 	}
+	if err != nil {
+		return nil, fmt.Errorf("PersonRepo - GetPersonForUpdate - row.Scan: %w: %v", entity.ErrInternal, err)
+	}
+
+	err = r.loadNationalities(ctx, []*entity.EnrichedPerson{person})
+	if err != nil {
+		return nil, fmt.Errorf("PersonRepo - GetPersonForUpdate - r.loadNationalities: %w: %v", entity.ErrInternal, err)
+	}
+
+	return person, nil
+}
+
+func (r *PersonRepo) SearchPeople(ctx context.Context, filter entity.PersonFilter, sort entity.PersonSort, after *entity.PersonCursor, offset, limit uint64) ([]*entity.EnrichedPerson, error) {
+	builder := r.Builder.
+		Select(_personColumns...).
+		From("people").
+		Where(personFilterPredicates(filter)).
+		OrderBy(personOrderBy(sort)...).
+		Limit(limit)
 
-	builder = builder.Limit(perPage)
-	if page > 1 {
-		builder = builder.Offset((page - 1) * perPage)
+	if after != nil {
+		builder = builder.Where(personKeysetPredicate(after))
+	}
+	if offset > 0 {
+		builder = builder.Offset(offset)
 	}
 
 	sql, args, _ := builder.ToSql()
-	rows, err := r.Pool.Query(ctx, sql, args...)
+	rows, err := r.Querier(ctx).Query(ctx, sql, args...)
 	if err != nil {
-		return nil, fmt.Errorf("PersonRepo - SearchPeople - p.Pool.Query: %v", err)
+		return nil, fmt.Errorf("PersonRepo - SearchPeople - r.Querier.Query: %w: %v", entity.ErrInternal, err)
 	}
 	defer rows.Close()
 
 	// Parse the query results into a slice of EnrichedPerson structs
 	people := []*entity.EnrichedPerson{}
 	for rows.Next() {
-		person := &entity.EnrichedPerson{}
-		err := rows.Scan(&person.ID, &person.Name, &person.Surname, &person.Patronymic, &person.Age, &person.Gender, &person.Nationality)
+		person, err := scanPerson(rows)
+		if err != nil {
+			return nil, fmt.Errorf("PersonRepo - SearchPeople - rows.Scan: %w: %v", entity.ErrInternal, err)
+		}
+		people = append(people, person)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("PersonRepo - SearchPeople - rows.Err: %w: %v", entity.ErrInternal, err)
+	}
+
+	err = r.loadNationalities(ctx, people)
+	if err != nil {
+		return nil, fmt.Errorf("PersonRepo - SearchPeople - r.loadNationalities: %w: %v", entity.ErrInternal, err)
+	}
+
+	return people, nil
+}
+
+// CountPeople returns the number of people matching the filter.
+func (r *PersonRepo) CountPeople(ctx context.Context, filter entity.PersonFilter) (int, error) {
+	sql, args, _ := r.Builder.
+		Select("count(*)").
+		From("people").
+		Where(personFilterPredicates(filter)).
+		ToSql()
+
+	var total int
+	err := r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("PersonRepo - CountPeople - row.Scan: %w: %v", entity.ErrInternal, err)
+	}
+
+	return total, nil
+}
+
+// ClaimPendingPeople picks up to limit people waiting for enrichment and leases
+// them until leaseUntil, so other workers skip them in the meantime.
+// People that failed maxAttempts times are left alone.
+func (r *PersonRepo) ClaimPendingPeople(ctx context.Context, limit, maxAttempts int, leaseUntil time.Time) ([]*entity.EnrichedPerson, error) {
+	pendingSQL, pendingArgs, _ := squirrel.
+		Select("id").
+		From("people").
+		Where(squirrel.Eq{"enrichment_status": []string{entity.EnrichmentPending, entity.EnrichmentFailed}}).
+		Where("enrichment_attempts < ?", maxAttempts).
+		Where("(enrichment_retry_at IS NULL OR enrichment_retry_at <= now())").
+		OrderBy("id").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+
+	sql, args, _ := r.Builder.
+		Update("people").
+		Set("enrichment_attempts", squirrel.Expr("enrichment_attempts + 1")).
+		Set("enrichment_retry_at", leaseUntil).
+		Where(squirrel.Expr("id IN ("+pendingSQL+")", pendingArgs...)).
+		Suffix("RETURNING " + strings.Join(_personColumns, ", ")).
+		ToSql()
+
+	rows, err := r.Querier(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("PersonRepo - ClaimPendingPeople - r.Querier.Query: %w: %v", entity.ErrInternal, err)
+	}
+	defer rows.Close()
+
+	people := []*entity.EnrichedPerson{}
+	for rows.Next() {
+		person, err := scanPerson(rows)
 		if err != nil {
-			return nil, fmt.Errorf("PersonRepo - SearchPeople - rows.Scan: %v", err)
+			return nil, fmt.Errorf("PersonRepo - ClaimPendingPeople - rows.Scan: %w: %v", entity.ErrInternal, err)
 		}
 		people = append(people, person)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("PersonRepo - SearchPeople - rows.Err: %v", err)
+		return nil, fmt.Errorf("PersonRepo - ClaimPendingPeople - rows.Err: %w: %v", entity.ErrInternal, err)
 	}
 
 	return people, nil
 }
+
+// FailEnrichment marks the person's enrichment as failed; it is retried after retryAt.
+func (r *PersonRepo) FailEnrichment(ctx context.Context, id int, retryAt time.Time) error {
+	sql, args, _ := r.Builder.
+		Update("people").
+		Set("enrichment_status", entity.EnrichmentFailed).
+		Set("version", squirrel.Expr("version + 1")).
+		Set("enrichment_retry_at", retryAt).
+		Where("id = ?", id).
+		ToSql()
+
+	_, err := r.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("PersonRepo - FailEnrichment - tx.Exec: %w: %v", entity.ErrInternal, err)
+	}
+
+	return nil
+}
+
+// insertNationalities stores the ranked candidate nationalities of a person.
+func (r *PersonRepo) insertNationalities(ctx context.Context, personID int, nationalities []entity.NationalityProbability) error {
+	if len(nationalities) == 0 {
+		return nil
+	}
+
+	builder := r.Builder.
+		Insert("person_nationalities").
+		Columns("person_id", "rank", "country_id", "probability")
+	for i, nationality := range nationalities {
+		builder = builder.Values(personID, i+1, nationality.CountryID, nationality.Probability)
+	}
+
+	sql, args, _ := builder.ToSql()
+	_, err := r.Querier(ctx).Exec(ctx, sql, args...)
+
+	return err
+}
+
+// loadNationalities fills the ranked candidate nationalities of the given people.
+func (r *PersonRepo) loadNationalities(ctx context.Context, people []*entity.EnrichedPerson) error {
+	if len(people) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*entity.EnrichedPerson, len(people))
+	ids := make([]int, 0, len(people))
+	for _, person := range people {
+		person.Nationalities = []entity.NationalityProbability{}
+		byID[person.ID] = person
+		ids = append(ids, person.ID)
+	}
+
+	sql, args, _ := r.Builder.
+		Select("person_id", "country_id", "probability").
+		From("person_nationalities").
+		Where(squirrel.Eq{"person_id": ids}).
+		OrderBy("person_id", "rank").
+		ToSql()
+
+	rows, err := r.Querier(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			personID    int
+			nationality entity.NationalityProbability
+		)
+		if err := rows.Scan(&personID, &nationality.CountryID, &nationality.Probability); err != nil {
+			return err
+		}
+		byID[personID].Nationalities = append(byID[personID].Nationalities, nationality)
+	}
+
+	return rows.Err()
+}
+
+// personFilterPredicates translates the filter into the conditions of a people query.
+func personFilterPredicates(filter entity.PersonFilter) squirrel.And {
+	predicates := squirrel.And{}
+
+	if filter.Name != "" {
+		predicates = append(predicates, squirrel.Eq{"name": filter.Name})
+	}
+	if filter.Surname != "" {
+		predicates = append(predicates, squirrel.Eq{"surname": filter.Surname})
+	}
+	if filter.Patronymic != "" {
+		predicates = append(predicates, squirrel.Eq{"patronymic": filter.Patronymic})
+	}
+	if filter.NamePrefix != "" {
+		predicates = append(predicates, squirrel.Like{"name": escapeLike(filter.NamePrefix) + "%"})
+	}
+	if filter.SurnameILike != "" {
+		predicates = append(predicates, squirrel.ILike{"surname": "%" + escapeLike(filter.SurnameILike) + "%"})
+	}
+	if filter.Age != nil {
+		predicates = append(predicates, squirrel.Eq{"age": *filter.Age})
+	}
+	if filter.AgeGTE != nil {
+		predicates = append(predicates, squirrel.GtOrEq{"age": *filter.AgeGTE})
+	}
+	if filter.AgeLTE != nil {
+		predicates = append(predicates, squirrel.LtOrEq{"age": *filter.AgeLTE})
+	}
+	if filter.Gender != "" {
+		predicates = append(predicates, squirrel.Eq{"gender": filter.Gender})
+	}
+	if filter.GenderNot != "" {
+		// People of unknown gender aren't of the excluded one either.
+		predicates = append(predicates, squirrel.Expr("gender IS DISTINCT FROM ?", filter.GenderNot))
+	}
+	if len(filter.Nationalities) > 0 {
+		predicates = append(predicates, squirrel.Eq{"nationality": filter.Nationalities})
+	}
+
+	return predicates
+}
+
+// personOrderBy translates the sort into the ORDER BY clauses of a people query.
+func personOrderBy(sort entity.PersonSort) []string {
+	clauses := make([]string, 0, len(sort))
+	for _, field := range sort {
+		// field.Field is one of the whitelisted column names
+		if field.Desc {
+			clauses = append(clauses, field.Field+" DESC")
+		} else {
+			clauses = append(clauses, field.Field)
+		}
+	}
+
+	return clauses
+}
+
+// _nullablePersonColumns are the sortable columns that may be NULL. PostgreSQL
+// sorts NULLs as if they were larger than any other value.
+var _nullablePersonColumns = map[string]bool{
+	"patronymic":  true,
+	"age":         true,
+	"gender":      true,
+	"nationality": true,
+}
+
+// personKeysetPredicate selects the people sorted after the cursor: those
+// whose sort key is equal to the cursor's up to some field and sorted after
+// it in that field.
+func personKeysetPredicate(after *entity.PersonCursor) squirrel.Or {
+	key := after.Key()
+	predicates := squirrel.Or{}
+
+	for i, field := range after.Sort {
+		predicate := squirrel.And{}
+		for j := 0; j < i; j++ {
+			if key[j] == nil {
+				predicate = append(predicate, squirrel.Expr(after.Sort[j].Field+" IS NULL"))
+			} else {
+				predicate = append(predicate, squirrel.Expr(after.Sort[j].Field+" = ?", key[j]))
+			}
+		}
+
+		column, nullable := field.Field, _nullablePersonColumns[field.Field]
+		switch {
+		case key[i] == nil && field.Desc:
+			predicate = append(predicate, squirrel.Expr(column+" IS NOT NULL"))
+		case key[i] == nil:
+			// Nothing is sorted after NULL in the ascending order.
+			continue
+		case field.Desc:
+			predicate = append(predicate, squirrel.Expr(column+" < ?", key[i]))
+		case nullable:
+			predicate = append(predicate, squirrel.Expr("("+column+" > ? OR "+column+" IS NULL)", key[i]))
+		default:
+			predicate = append(predicate, squirrel.Expr(column+" > ?", key[i]))
+		}
+
+		predicates = append(predicates, predicate)
+	}
+
+	return predicates
+}
+
+// escapeLike makes the wildcards of a LIKE pattern match literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// missingPersonError tells why a person wasn't found under its expected version.
+func (r *PersonRepo) missingPersonError(ctx context.Context, id int) error {
+	sql, args, _ := r.Builder.
+		Select("1").
+		From("people").
+		Where("id = ?", id).
+		ToSql()
+
+	var exists int
+	err := r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&exists)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.ErrPersonNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", entity.ErrInternal, err)
+	}
+
+	return entity.ErrPersonModified
+}
+
+// isDuplicatePerson reports whether err is a violation of the unique full name index.
+func isDuplicatePerson(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == _uniqueViolation && pgErr.ConstraintName == _fullNameKey
+}
+
+// duplicatePersonError points to the person already stored under the full name of person.
+func (r *PersonRepo) duplicatePersonError(ctx context.Context, person *entity.EnrichedPerson) error {
+	sql, args, _ := r.Builder.
+		Select("id").
+		From("people").
+		Where("lower(name) = lower(?)", person.Name).
+		Where("lower(surname) = lower(?)", person.Surname).
+		Where("lower(COALESCE(patronymic, '')) = lower(?)", person.Patronymic).
+		ToSql()
+
+	// The transaction is aborted by the violation, so look outside of it.
+	var id int
+	if err := r.Pool.QueryRow(ctx, sql, args...).Scan(&id); err != nil {
+		// The other person may have been deleted in the meantime.
+		return entity.ErrPersonExists
+	}
+
+	return &entity.DuplicatePersonError{ExistingID: id}
+}
+
+func scanPerson(row pgx.Row) (*entity.EnrichedPerson, error) {
+	person := &entity.EnrichedPerson{}
+	err := row.Scan(
+		&person.ID, &person.Name, &person.Surname, &person.Patronymic, &person.Age, &person.AgeCount, &person.Gender,
+		&person.GenderProbability, &person.Nationality, &person.LowConfidence, &person.CountryHint, &person.EnrichmentStatus,
+		&person.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return person, nil
+}
@@ -0,0 +1,155 @@
+package postgresdb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/realPointer/EnrichInfo/internal/entity"
+)
+
+func TestPersonKeysetPredicate(t *testing.T) {
+	age := 30
+
+	tests := []struct {
+		name     string
+		sort     string
+		after    *entity.EnrichedPerson
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "id only",
+			sort:     "",
+			after:    &entity.EnrichedPerson{ID: 7},
+			wantSQL:  "((id > ?))",
+			wantArgs: []any{7},
+		},
+		{
+			name:     "descending id",
+			sort:     "-id",
+			after:    &entity.EnrichedPerson{ID: 7},
+			wantSQL:  "((id < ?))",
+			wantArgs: []any{7},
+		},
+		{
+			name:     "not null ascending",
+			sort:     "surname",
+			after:    &entity.EnrichedPerson{ID: 7, Surname: "Smith"},
+			wantSQL:  "((surname > ?) OR (surname = ? AND id > ?))",
+			wantArgs: []any{"Smith", "Smith", 7},
+		},
+		{
+			name:     "nullable ascending after a value takes the NULLs too",
+			sort:     "age",
+			after:    &entity.EnrichedPerson{ID: 7, Age: &age},
+			wantSQL:  "(((age > ? OR age IS NULL)) OR (age = ? AND id > ?))",
+			wantArgs: []any{30, 30, 7},
+		},
+		{
+			name:     "nullable ascending after NULL only takes the other NULLs",
+			sort:     "age",
+			after:    &entity.EnrichedPerson{ID: 7},
+			wantSQL:  "((age IS NULL AND id > ?))",
+			wantArgs: []any{7},
+		},
+		{
+			name:     "nullable descending after a value skips the NULLs sorted first",
+			sort:     "-age",
+			after:    &entity.EnrichedPerson{ID: 7, Age: &age},
+			wantSQL:  "((age < ?) OR (age = ? AND id > ?))",
+			wantArgs: []any{30, 30, 7},
+		},
+		{
+			name:     "nullable descending after NULL takes every value",
+			sort:     "-age",
+			after:    &entity.EnrichedPerson{ID: 7},
+			wantSQL:  "((age IS NOT NULL) OR (age IS NULL AND id > ?))",
+			wantArgs: []any{7},
+		},
+		{
+			name:     "several fields",
+			sort:     "-age,surname",
+			after:    &entity.EnrichedPerson{ID: 7, Surname: "Smith", Age: &age},
+			wantSQL:  "((age < ?) OR (age = ? AND surname > ?) OR (age = ? AND surname = ? AND id > ?))",
+			wantArgs: []any{30, 30, "Smith", 30, "Smith", 7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sort, err := entity.ParsePersonSort(tt.sort)
+			if err != nil {
+				t.Fatalf("ParsePersonSort(%q) error = %v", tt.sort, err)
+			}
+
+			sql, args, err := personKeysetPredicate(entity.NewPersonCursor(entity.PersonFilter{}, sort, tt.after)).ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("sql = %s\nwant  %s", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestPersonFilterPredicates(t *testing.T) {
+	age := 18
+
+	tests := []struct {
+		name     string
+		filter   entity.PersonFilter
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:    "empty filter",
+			filter:  entity.PersonFilter{},
+			wantSQL: "(1=1)",
+		},
+		{
+			name:     "prefix and substring are escaped",
+			filter:   entity.PersonFilter{NamePrefix: `50%_`, SurnameILike: `a\b`},
+			wantSQL:  "(name LIKE ? AND surname ILIKE ?)",
+			wantArgs: []any{`50\%\_%`, `%a\\b%`},
+		},
+		{
+			name:     "ranges and sets",
+			filter:   entity.PersonFilter{AgeGTE: &age, GenderNot: entity.GenderMale, Nationalities: []string{"RU", "US"}},
+			wantSQL:  "(age >= ? AND gender IS DISTINCT FROM ? AND nationality IN (?,?))",
+			wantArgs: []any{18, "male", "RU", "US"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := personFilterPredicates(tt.filter).ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("sql = %s\nwant  %s", sql, tt.wantSQL)
+			}
+			if len(args) > 0 || len(tt.wantArgs) > 0 {
+				if !reflect.DeepEqual(args, tt.wantArgs) {
+					t.Errorf("args = %v, want %v", args, tt.wantArgs)
+				}
+			}
+		})
+	}
+}
+
+func TestPersonOrderBy(t *testing.T) {
+	sort, err := entity.ParsePersonSort("-age,surname")
+	if err != nil {
+		t.Fatalf("ParsePersonSort() error = %v", err)
+	}
+
+	want := []string{"age DESC", "surname", "id"}
+	if got := personOrderBy(sort); !reflect.DeepEqual(got, want) {
+		t.Errorf("personOrderBy() = %v, want %v", got, want)
+	}
+}
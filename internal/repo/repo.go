@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"time"
 
 	"github.com/realPointer/EnrichInfo/internal/entity"
 	"github.com/realPointer/EnrichInfo/internal/repo/postgresdb"
@@ -9,19 +10,48 @@ import (
 )
 
 type Person interface {
-	CreatePerson(ctx context.Context, person *entity.EnrichedPerson) error
+	CreatePerson(ctx context.Context, person *entity.EnrichedPerson) (*entity.EnrichedPerson, error)
 	UpdatePerson(ctx context.Context, id int, updatedPerson *entity.EnrichedPerson) error
-	DeletePerson(ctx context.Context, id int) error
+	DeletePerson(ctx context.Context, id, version int) error
 	GetPerson(ctx context.Context, id int) (*entity.EnrichedPerson, error)
-	SearchPeople(ctx context.Context, filters map[string]string, page, perPage uint64) ([]*entity.EnrichedPerson, error)
+	GetPersonForUpdate(ctx context.Context, id int) (*entity.EnrichedPerson, error)
+	SearchPeople(ctx context.Context, filter entity.PersonFilter, sort entity.PersonSort, after *entity.PersonCursor, offset, limit uint64) ([]*entity.EnrichedPerson, error)
+	CountPeople(ctx context.Context, filter entity.PersonFilter) (int, error)
+	ClaimPendingPeople(ctx context.Context, limit, maxAttempts int, leaseUntil time.Time) ([]*entity.EnrichedPerson, error)
+	FailEnrichment(ctx context.Context, id int, retryAt time.Time) error
+}
+
+type EnrichmentCache interface {
+	GetEnrichment(ctx context.Context, name, countryHint string) (*entity.Enrichment, error)
+	SaveEnrichment(ctx context.Context, name, countryHint string, enrichment *entity.Enrichment, expiresAt time.Time) error
+	DeleteExpiredEnrichments(ctx context.Context) (int64, error)
+}
+
+type Idempotency interface {
+	ReserveIdempotencyKey(ctx context.Context, key, fingerprint string, expiresAt time.Time) (*entity.IdempotencyRecord, error)
+	SaveIdempotentResponse(ctx context.Context, key string, response *entity.IdempotentResponse, expiresAt time.Time) error
+	DeleteIdempotencyKey(ctx context.Context, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+}
+
+// Transactor runs units of work: repository calls made with the context
+// passed to fn are committed or rolled back together.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type Repositories struct {
+	Transactor
 	Person
+	EnrichmentCache
+	Idempotency
 }
 
 func NewRepositories(pg *postgres.Postgres) *Repositories {
 	return &Repositories{
-		Person: postgresdb.NewPersonRepo(pg),
+		Transactor:      pg,
+		Person:          postgresdb.NewPersonRepo(pg),
+		EnrichmentCache: postgresdb.NewEnrichmentCacheRepo(pg),
+		Idempotency:     postgresdb.NewIdempotencyRepo(pg),
 	}
 }
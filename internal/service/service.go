@@ -2,32 +2,59 @@ package service
 
 import (
 	"context"
+	"time"
 
+	"github.com/realPointer/EnrichInfo/internal/enricher"
 	"github.com/realPointer/EnrichInfo/internal/entity"
 	"github.com/realPointer/EnrichInfo/internal/repo"
 	"github.com/realPointer/EnrichInfo/internal/service/services"
+	"github.com/realPointer/EnrichInfo/pkg/namecase"
 )
 
 //go:generate mockgen -source=service.go -destination=mocks/mock.go
 
 type Person interface {
-	CreatePerson(ctx context.Context, person *entity.EnrichedPerson) error
-	UpdatePerson(ctx context.Context, id int, updatedPerson *entity.EnrichedPerson) error
-	DeletePerson(ctx context.Context, id int) error
+	CreatePerson(ctx context.Context, person *entity.PersonInput) (*entity.EnrichedPerson, error)
+	UpdatePerson(ctx context.Context, id int, person *entity.EnrichedPerson, match entity.VersionMatch) (*entity.EnrichedPerson, error)
+	PatchPerson(ctx context.Context, id int, patch entity.PersonPatch, locale string, match entity.VersionMatch) (*entity.EnrichedPerson, error)
+	DeletePerson(ctx context.Context, id int, match entity.VersionMatch) error
 	GetPerson(ctx context.Context, id int) (*entity.EnrichedPerson, error)
-	SearchPeople(ctx context.Context, filters map[string]string, page, perPage uint64) ([]*entity.EnrichedPerson, error)
+	SearchPeople(ctx context.Context, filter entity.PersonFilter, sort entity.PersonSort, after *entity.PersonCursor, page, perPage uint64) (*entity.PeoplePage, error)
+}
+
+type Enrichment interface {
+	CacheStats() entity.CacheStats
+}
+
+type Idempotency interface {
+	Begin(ctx context.Context, key, fingerprint string) (*entity.IdempotentResponse, error)
+	Complete(ctx context.Context, key string, response *entity.IdempotentResponse) error
+	Abort(ctx context.Context, key string) error
 }
 
 type Services struct {
 	Person
+	Enrichment
+	Idempotency
 }
 
 type ServicesDependencies struct {
-	Repos *repo.Repositories
+	Repos           *repo.Repositories
+	Enricher        enricher.Enricher
+	EnrichmentCache enricher.Cache
+	Names           *namecase.Normalizer
+	// AsyncEnrichment makes CreatePerson store people as pending instead of enriching them inline.
+	AsyncEnrichment bool
+	// IdempotencyTTL is how long the responses to requests with an Idempotency-Key are kept.
+	IdempotencyTTL time.Duration
+	// IdempotencyLease is how long a request in progress holds its Idempotency-Key.
+	IdempotencyLease time.Duration
 }
 
 func NewServices(deps ServicesDependencies) *Services {
 	return &Services{
-		Person: services.NewPersonService(deps.Repos.Person),
+		Person:      services.NewPersonService(deps.Repos.Transactor, deps.Repos.Person, deps.Enricher, deps.Names, deps.AsyncEnrichment),
+		Enrichment:  services.NewEnrichmentService(deps.EnrichmentCache),
+		Idempotency: services.NewIdempotencyService(deps.Repos.Idempotency, deps.IdempotencyTTL, deps.IdempotencyLease),
 	}
 }
@@ -0,0 +1,20 @@
+package services
+
+import (
+	"github.com/realPointer/EnrichInfo/internal/enricher"
+	"github.com/realPointer/EnrichInfo/internal/entity"
+)
+
+type EnrichmentService struct {
+	cache enricher.Cache
+}
+
+func NewEnrichmentService(cache enricher.Cache) *EnrichmentService {
+	return &EnrichmentService{
+		cache: cache,
+	}
+}
+
+func (s *EnrichmentService) CacheStats() entity.CacheStats {
+	return s.cache.Stats()
+}
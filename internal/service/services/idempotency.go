@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/realPointer/EnrichInfo/internal/entity"
+	"github.com/realPointer/EnrichInfo/internal/repo"
+)
+
+type IdempotencyService struct {
+	idempotencyRepo repo.Idempotency
+	// ttl is how long a response is replayed, lease how long a request in
+	// progress holds its key, so a crashed one doesn't block retries for the whole ttl.
+	ttl   time.Duration
+	lease time.Duration
+}
+
+func NewIdempotencyService(idempotencyRepo repo.Idempotency, ttl, lease time.Duration) *IdempotencyService {
+	return &IdempotencyService{
+		idempotencyRepo: idempotencyRepo,
+		ttl:             ttl,
+		lease:           lease,
+	}
+}
+
+// Begin reserves key for the request with the given fingerprint. It returns
+// the stored response if the request was already processed, or nil if the
+// request has to be processed and then completed or aborted.
+func (s *IdempotencyService) Begin(ctx context.Context, key, fingerprint string) (*entity.IdempotentResponse, error) {
+	record, err := s.idempotencyRepo.ReserveIdempotencyKey(ctx, key, fingerprint, time.Now().Add(s.lease))
+	if err != nil {
+		return nil, fmt.Errorf("IdempotencyService - Begin - s.idempotencyRepo.ReserveIdempotencyKey: %w", err)
+	}
+
+	switch {
+	case record == nil:
+		return nil, nil
+	case record.Fingerprint != fingerprint:
+		return nil, entity.ErrIdempotencyKeyReused
+	case record.Response == nil:
+		return nil, entity.ErrIdempotencyKeyInUse
+	default:
+		return record.Response, nil
+	}
+}
+
+// Complete stores the response to replay for key.
+func (s *IdempotencyService) Complete(ctx context.Context, key string, response *entity.IdempotentResponse) error {
+	return s.idempotencyRepo.SaveIdempotentResponse(ctx, key, response, time.Now().Add(s.ttl))
+}
+
+// Abort releases key, so the request can be retried with it.
+func (s *IdempotencyService) Abort(ctx context.Context, key string) error {
+	return s.idempotencyRepo.DeleteIdempotencyKey(ctx, key)
+}
@@ -2,37 +2,271 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
 
+	"github.com/realPointer/EnrichInfo/internal/enricher"
 	"github.com/realPointer/EnrichInfo/internal/entity"
 	"github.com/realPointer/EnrichInfo/internal/repo"
+	"github.com/realPointer/EnrichInfo/pkg/namecase"
 )
 
 type PersonService struct {
+	transactor repo.Transactor
 	personRepo repo.Person
+	enricher   enricher.Enricher
+	names      *namecase.Normalizer
+	// async stores new people as pending and leaves their enrichment to the background worker.
+	async bool
 }
 
-func NewPersonService(personRepo repo.Person) *PersonService {
+func NewPersonService(transactor repo.Transactor, personRepo repo.Person, enricher enricher.Enricher, names *namecase.Normalizer, async bool) *PersonService {
 	return &PersonService{
+		transactor: transactor,
 		personRepo: personRepo,
+		enricher:   enricher,
+		names:      names,
+		async:      async,
 	}
 }
 
-func (s *PersonService) CreatePerson(ctx context.Context, person *entity.EnrichedPerson) error {
-	return s.personRepo.CreatePerson(ctx, person)
+func (s *PersonService) CreatePerson(ctx context.Context, person *entity.PersonInput) (*entity.EnrichedPerson, error) {
+	verr := &entity.ValidationError{}
+	person.Name = s.normalizeName(verr, "name", person.Name, person.Locale)
+	person.Surname = s.normalizeName(verr, "surname", person.Surname, person.Locale)
+	person.Patronymic = s.normalizeName(verr, "patronymic", person.Patronymic, person.Locale)
+	if err := verr.Err(); err != nil {
+		return nil, fmt.Errorf("PersonService - CreatePerson - s.normalizeName: %w", err)
+	}
+
+	var enrichedPerson *entity.EnrichedPerson
+
+	if s.async {
+		enrichedPerson = &entity.EnrichedPerson{
+			Name:             person.Name,
+			Surname:          person.Surname,
+			Patronymic:       person.Patronymic,
+			CountryHint:      person.CountryHint,
+			EnrichmentStatus: entity.EnrichmentPending,
+		}
+	} else {
+		var err error
+		enrichedPerson, err = s.enricher.Enrich(ctx, person)
+		if err != nil {
+			return nil, fmt.Errorf("PersonService - CreatePerson - s.enricher.Enrich: %w", err)
+		}
+		enrichedPerson.EnrichmentStatus = entity.EnrichmentDone
+	}
+
+	return s.personRepo.CreatePerson(ctx, enrichedPerson)
+}
+
+// UpdatePerson replaces the person with the given one. The attributes are
+// stored as they are given, so a person is never re-enriched by a replacement.
+func (s *PersonService) UpdatePerson(ctx context.Context, id int, person *entity.EnrichedPerson, match entity.VersionMatch) (*entity.EnrichedPerson, error) {
+	verr := &entity.ValidationError{}
+	person.ID = id
+	person.Name = s.normalizeName(verr, "name", person.Name, person.Locale)
+	person.Surname = s.normalizeName(verr, "surname", person.Surname, person.Locale)
+	person.Patronymic = s.normalizeName(verr, "patronymic", person.Patronymic, person.Locale)
+	if err := verr.Err(); err != nil {
+		return nil, fmt.Errorf("PersonService - UpdatePerson - s.normalizeName: %w", err)
+	}
+	person.EnrichmentStatus = entity.EnrichmentDone
+	if person.Nationalities == nil {
+		person.Nationalities = []entity.NationalityProbability{}
+	}
+
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		// Lock the person, so it can't be changed or deleted until it's replaced.
+		previousPerson, err := s.personRepo.GetPersonForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !match.Matches(previousPerson.Version) {
+			return entity.ErrPersonModified
+		}
+		person.Version = previousPerson.Version
+
+		return s.personRepo.UpdatePerson(ctx, id, person)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("PersonService - UpdatePerson - s.transactor.WithinTx: %w", err)
+	}
+
+	return person, nil
+}
+
+// PatchPerson applies a merge patch to the person. If the patch changes the
+// full name or the country hint, the person is re-enriched; attributes set
+// by the patch take precedence over the predicted ones. The person stays
+// locked while it is re-enriched, so concurrent changes wait for the patch.
+// The names set by the patch are capitalized for locale, the language of the
+// request, unless the patch sets the locale itself.
+func (s *PersonService) PatchPerson(ctx context.Context, id int, patch entity.PersonPatch, locale string, match entity.VersionMatch) (*entity.EnrichedPerson, error) {
+	var person *entity.EnrichedPerson
+
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		previousPerson, err := s.personRepo.GetPersonForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !match.Matches(previousPerson.Version) {
+			return entity.ErrPersonModified
+		}
+
+		person, err = s.patchPerson(ctx, previousPerson, patch, locale)
+		if err != nil {
+			return err
+		}
+
+		return s.personRepo.UpdatePerson(ctx, id, person)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("PersonService - PatchPerson - s.transactor.WithinTx: %w", err)
+	}
+
+	return person, nil
+}
+
+// normalizeName capitalizes the name for the locale. Some letters expand when
+// their case changes, like "ß" to "SS", so the limit is checked once more.
+func (s *PersonService) normalizeName(verr *entity.ValidationError, field, name, locale string) string {
+	name = s.names.Normalize(name, locale)
+	if utf8.RuneCountInString(name) > entity.MaxNameLength {
+		verr.Add(field, fmt.Sprintf("must be at most %d characters long", entity.MaxNameLength))
+	}
+
+	return name
 }
 
-func (s *PersonService) UpdatePerson(ctx context.Context, id int, updatedPerson *entity.EnrichedPerson) error {
-	return s.personRepo.UpdatePerson(ctx, id, updatedPerson)
+// patchPerson returns the previous person with the patch applied, re-enriched if needed.
+func (s *PersonService) patchPerson(ctx context.Context, previousPerson *entity.EnrichedPerson, patch entity.PersonPatch, locale string) (*entity.EnrichedPerson, error) {
+	person, err := patch.Apply(previousPerson, locale)
+	if err != nil {
+		return nil, fmt.Errorf("PersonService - patchPerson - patch.Apply: %w", err)
+	}
+	person.Version = previousPerson.Version
+
+	// Normalization depends on the locale, so a name is only normalized again
+	// if the patch sets it or the locale; the others are kept byte for byte.
+	// The locale of the request doesn't count as setting the locale.
+	verr := &entity.ValidationError{}
+	renamed := false
+	for _, field := range []struct {
+		name              string
+		patched, previous *string
+	}{
+		{"name", &person.Name, &previousPerson.Name},
+		{"surname", &person.Surname, &previousPerson.Surname},
+		{"patronymic", &person.Patronymic, &previousPerson.Patronymic},
+	} {
+		if !patch.Has(field.name) && !patch.Has("locale") {
+			continue
+		}
+		// Capitalization doesn't change the predictions.
+		if patch.Has(field.name) && !strings.EqualFold(*field.patched, *field.previous) {
+			renamed = true
+		}
+		*field.patched = s.normalizeName(verr, field.name, *field.patched, person.Locale)
+	}
+	if err := verr.Err(); err != nil {
+		return nil, fmt.Errorf("PersonService - patchPerson - s.normalizeName: %w", err)
+	}
+	if patch.Has("country_hint") && person.CountryHint != previousPerson.CountryHint {
+		renamed = true
+	}
+
+	if renamed {
+		enrichedPerson, err := s.enricher.Enrich(ctx, &entity.PersonInput{
+			Name:        person.Name,
+			Surname:     person.Surname,
+			Patronymic:  person.Patronymic,
+			CountryHint: person.CountryHint,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("PersonService - patchPerson - s.enricher.Enrich: %w", err)
+		}
+
+		// Keep the attributes set by the patch instead of the predicted ones
+		enrichment := enrichedPerson.Enrichment()
+		if patch.Has(entity.AttributeAge) {
+			enrichment.Age = person.Age
+		}
+		if patch.Has(entity.AttributeGender) {
+			enrichment.Gender = person.Gender
+		}
+		if patch.Has(entity.AttributeNationality) {
+			enrichment.Nationality = person.Nationality
+		}
+		person.SetEnrichment(enrichment)
+		person.LowConfidence = enrichedPerson.LowConfidence
+		person.EnrichmentStatus = entity.EnrichmentDone
+	}
+
+	// Attributes set by the client aren't low-confidence predictions anymore.
+	for _, attribute := range []string{entity.AttributeAge, entity.AttributeGender, entity.AttributeNationality} {
+		if patch.Has(attribute) {
+			person.SetByClient(attribute)
+		}
+	}
+
+	return person, nil
 }
 
-func (s *PersonService) DeletePerson(ctx context.Context, id int) error {
-	return s.personRepo.DeletePerson(ctx, id)
+func (s *PersonService) DeletePerson(ctx context.Context, id int, match entity.VersionMatch) error {
+	if match == nil {
+		return s.personRepo.DeletePerson(ctx, id, 0)
+	}
+
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		person, err := s.personRepo.GetPersonForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !match.Matches(person.Version) {
+			return entity.ErrPersonModified
+		}
+
+		return s.personRepo.DeletePerson(ctx, id, person.Version)
+	})
+	if err != nil {
+		return fmt.Errorf("PersonService - DeletePerson - s.transactor.WithinTx: %w", err)
+	}
+
+	return nil
 }
 
 func (s *PersonService) GetPerson(ctx context.Context, id int) (*entity.EnrichedPerson, error) {
 	return s.personRepo.GetPerson(ctx, id)
 }
 
-func (s *PersonService) SearchPeople(ctx context.Context, filters map[string]string, page, perPage uint64) ([]*entity.EnrichedPerson, error) {
-	return s.personRepo.SearchPeople(ctx, filters, page, perPage)
+// SearchPeople returns a page of the people matching the filter and their
+// total number. The page starts after the cursor if there is one, and is
+// numbered otherwise; page is 0 for the pages of a cursor pagination.
+func (s *PersonService) SearchPeople(ctx context.Context, filter entity.PersonFilter, sort entity.PersonSort, after *entity.PersonCursor, page, perPage uint64) (*entity.PeoplePage, error) {
+	var offset uint64
+	if after == nil && page > 1 {
+		offset = (page - 1) * perPage
+	}
+
+	// One more person tells whether there is a next page.
+	people, err := s.personRepo.SearchPeople(ctx, filter, sort, after, offset, perPage+1)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.personRepo.CountPeople(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entity.PeoplePage{Items: people, Total: total, Page: page, PerPage: perPage}
+	if uint64(len(people)) > perPage {
+		result.Items = people[:perPage]
+		result.NextCursor = entity.NewPersonCursor(filter, sort, people[perPage-1]).Encode()
+	}
+
+	return result, nil
 }
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/realPointer/EnrichInfo/internal/entity"
+	"github.com/realPointer/EnrichInfo/internal/repo"
+	"github.com/realPointer/EnrichInfo/pkg/namecase"
+)
+
+type stubTransactor struct{}
+
+func (stubTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type stubPersonRepo struct {
+	repo.Person
+	person *entity.EnrichedPerson
+}
+
+func (r *stubPersonRepo) CreatePerson(ctx context.Context, person *entity.EnrichedPerson) (*entity.EnrichedPerson, error) {
+	r.person = person
+	return person, nil
+}
+
+func (r *stubPersonRepo) GetPersonForUpdate(ctx context.Context, id int) (*entity.EnrichedPerson, error) {
+	person := *r.person
+	return &person, nil
+}
+
+func (r *stubPersonRepo) UpdatePerson(ctx context.Context, id int, person *entity.EnrichedPerson) error {
+	r.person = person
+	return nil
+}
+
+type stubEnricher struct {
+	calls int
+}
+
+func (e *stubEnricher) Enrich(ctx context.Context, person *entity.PersonInput) (*entity.EnrichedPerson, error) {
+	e.calls++
+
+	age, gender := 50, entity.GenderMale
+	return &entity.EnrichedPerson{Age: &age, Gender: &gender}, nil
+}
+
+func TestPatchPerson(t *testing.T) {
+	gender := entity.GenderFemale
+	stored := entity.EnrichedPerson{
+		ID: 1, Name: "İsmail", Surname: "IJsselmeer", Gender: &gender, EnrichmentStatus: entity.EnrichmentDone,
+	}
+
+	tests := []struct {
+		name         string
+		body         string
+		locale       string
+		wantName     string
+		wantSurname  string
+		wantEnriched bool
+		wantGender   string
+	}{
+		{
+			name:        "other fields keep the names byte for byte",
+			body:        `{"age": 31}`,
+			wantName:    "İsmail",
+			wantSurname: "IJsselmeer",
+			wantGender:  entity.GenderFemale,
+		},
+		{
+			name:        "the request locale doesn't renormalize untouched names",
+			body:        `{"age": 31}`,
+			locale:      "en",
+			wantName:    "İsmail",
+			wantSurname: "IJsselmeer",
+			wantGender:  entity.GenderFemale,
+		},
+		{
+			name:        "a patched name is normalized",
+			body:        `{"surname": "ijsselmeer", "locale": "nl"}`,
+			wantName:    namecase.New().Normalize("İsmail", "nl"),
+			wantSurname: "IJsselmeer",
+			wantGender:  entity.GenderFemale,
+		},
+		{
+			name:         "a patched name is normalized for the request locale",
+			body:         `{"name": "ijsbrand"}`,
+			locale:       "nl",
+			wantName:     "IJsbrand",
+			wantSurname:  "IJsselmeer",
+			wantEnriched: true,
+			wantGender:   entity.GenderMale,
+		},
+		{
+			name:        "a capitalization change doesn't re-enrich",
+			body:        `{"surname": "IJSSELMEER"}`,
+			wantName:    "İsmail",
+			wantSurname: "Ijsselmeer",
+			wantGender:  entity.GenderFemale,
+		},
+		{
+			name:         "a new name re-enriches",
+			body:         `{"name": "jan"}`,
+			wantName:     "Jan",
+			wantSurname:  "IJsselmeer",
+			wantEnriched: true,
+			wantGender:   entity.GenderMale,
+		},
+		{
+			name:         "patched attributes win over the re-enrichment",
+			body:         `{"name": "jan", "gender": "female"}`,
+			wantName:     "Jan",
+			wantSurname:  "IJsselmeer",
+			wantEnriched: true,
+			wantGender:   entity.GenderFemale,
+		},
+		{
+			name:        "a patched locale renormalizes every name",
+			body:        `{"locale": "en"}`,
+			wantName:    namecase.New().Normalize("İsmail", "en"),
+			wantSurname: "Ijsselmeer",
+			wantGender:  entity.GenderFemale,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			person := stored
+			personRepo := &stubPersonRepo{person: &person}
+			enricher := &stubEnricher{}
+			s := NewPersonService(stubTransactor{}, personRepo, enricher, namecase.New(), false)
+
+			patch, err := entity.ParsePersonPatch([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("ParsePersonPatch() error = %v", err)
+			}
+			if err := patch.Bind(httptest.NewRequest("PATCH", "/v1/people/1", nil)); err != nil {
+				t.Fatalf("Bind() error = %v", err)
+			}
+
+			got, err := s.PatchPerson(context.Background(), 1, patch, tt.locale, nil)
+			if err != nil {
+				t.Fatalf("PatchPerson() error = %v", err)
+			}
+
+			if got.Name != tt.wantName || got.Surname != tt.wantSurname {
+				t.Errorf("name = %q %q, want %q %q", got.Name, got.Surname, tt.wantName, tt.wantSurname)
+			}
+			if (enricher.calls > 0) != tt.wantEnriched {
+				t.Errorf("enriched = %v, want %v", enricher.calls > 0, tt.wantEnriched)
+			}
+			if got.Gender == nil || *got.Gender != tt.wantGender {
+				t.Errorf("gender = %v, want %s", got.Gender, tt.wantGender)
+			}
+		})
+	}
+}
+
+func TestNormalizedNameTooLong(t *testing.T) {
+	// "ß" becomes "Ss", so the name outgrows the limit only once it's normalized.
+	long := "ß" + strings.Repeat("a", entity.MaxNameLength-1)
+	stored := entity.EnrichedPerson{ID: 1, Name: "Dmitriy", Surname: "Ushakov", EnrichmentStatus: entity.EnrichmentDone}
+
+	tests := []struct {
+		name string
+		call func(s *PersonService) error
+	}{
+		{
+			name: "create",
+			call: func(s *PersonService) error {
+				_, err := s.CreatePerson(context.Background(), &entity.PersonInput{Name: "Dmitriy", Surname: long, Locale: "de"})
+				return err
+			},
+		},
+		{
+			name: "update",
+			call: func(s *PersonService) error {
+				_, err := s.UpdatePerson(context.Background(), 1, &entity.EnrichedPerson{Name: "Dmitriy", Surname: long, Locale: "de"}, nil)
+				return err
+			},
+		},
+		{
+			name: "patch",
+			call: func(s *PersonService) error {
+				patch, err := entity.ParsePersonPatch([]byte(`{"surname": "` + long + `", "locale": "de"}`))
+				if err != nil {
+					t.Fatalf("ParsePersonPatch() error = %v", err)
+				}
+				if err := patch.Bind(httptest.NewRequest("PATCH", "/v1/people/1", nil)); err != nil {
+					t.Fatalf("Bind() error = %v", err)
+				}
+				_, err = s.PatchPerson(context.Background(), 1, patch, "", nil)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			person := stored
+			personRepo := &stubPersonRepo{person: &person}
+			enricher := &stubEnricher{}
+			s := NewPersonService(stubTransactor{}, personRepo, enricher, namecase.New(), false)
+
+			err := tt.call(s)
+
+			var verr *entity.ValidationError
+			if !errors.As(err, &verr) || len(verr.Fields) != 1 || verr.Fields[0].Field != "surname" {
+				t.Fatalf("error = %v, want a validation error of the surname", err)
+			}
+			if personRepo.person != &person || person.Surname != stored.Surname || enricher.calls != 0 {
+				t.Errorf("person was stored or enriched despite the invalid surname")
+			}
+		})
+	}
+}
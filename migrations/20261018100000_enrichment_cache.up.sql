@@ -0,0 +1,9 @@
+CREATE TABLE enrichment_cache (
+    name VARCHAR(255) NOT NULL,
+    country_hint VARCHAR(2) NOT NULL DEFAULT '',
+    data JSONB NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (name, country_hint)
+);
+
+CREATE INDEX enrichment_cache_expires_at_idx ON enrichment_cache (expires_at);
@@ -0,0 +1,7 @@
+ALTER TABLE people
+    ADD COLUMN country_hint VARCHAR(2) NOT NULL DEFAULT '',
+    ADD COLUMN enrichment_status VARCHAR(20) NOT NULL DEFAULT 'done',
+    ADD COLUMN enrichment_attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN enrichment_retry_at TIMESTAMPTZ;
+
+CREATE INDEX people_enrichment_queue_idx ON people (id) WHERE enrichment_status IN ('pending', 'failed');
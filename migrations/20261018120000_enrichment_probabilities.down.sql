@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS person_nationalities;
+
+ALTER TABLE people
+    DROP COLUMN IF EXISTS gender_probability,
+    DROP COLUMN IF EXISTS age_count;
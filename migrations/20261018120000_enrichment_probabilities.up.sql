@@ -0,0 +1,11 @@
+ALTER TABLE people
+    ADD COLUMN age_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN gender_probability DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+CREATE TABLE person_nationalities (
+    person_id INT NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    rank SMALLINT NOT NULL,
+    country_id VARCHAR(10) NOT NULL,
+    probability DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (person_id, rank)
+);
\ No newline at end of file
@@ -0,0 +1,6 @@
+UPDATE people
+SET age = COALESCE(age, 0),
+    gender = COALESCE(gender, ''),
+    nationality = COALESCE(nationality, '');
+
+ALTER TABLE people DROP COLUMN IF EXISTS low_confidence;
@@ -0,0 +1,7 @@
+ALTER TABLE people ADD COLUMN low_confidence TEXT[];
+
+-- Unknown attributes used to be stored as zero values.
+UPDATE people
+SET age = NULLIF(age, 0),
+    gender = NULLIF(gender, ''),
+    nationality = NULLIF(nationality, '');
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS people_full_name_key;
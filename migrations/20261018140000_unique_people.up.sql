@@ -0,0 +1,22 @@
+-- People stored more than once must be merged by hand first, see README.
+DO $$
+DECLARE
+    duplicates text;
+BEGIN
+    SELECT string_agg(ids, '; ')
+    INTO duplicates
+    FROM (
+        SELECT string_agg(id::text, ', ' ORDER BY id) AS ids
+        FROM people
+        GROUP BY lower(name), lower(surname), lower(COALESCE(patronymic, ''))
+        HAVING count(*) > 1
+    ) groups;
+
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'people with the same full name must be merged before adding people_full_name_key: %', duplicates
+            USING HINT = 'Keep one person of every group of IDs and delete the others.';
+    END IF;
+END
+$$;
+
+CREATE UNIQUE INDEX people_full_name_key ON people (lower(name), lower(surname), lower(COALESCE(patronymic, '')));
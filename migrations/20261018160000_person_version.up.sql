@@ -0,0 +1 @@
+ALTER TABLE people ADD COLUMN version INT NOT NULL DEFAULT 1;
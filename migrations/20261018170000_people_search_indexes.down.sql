@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS people_nationality_idx;
+DROP INDEX IF EXISTS people_gender_idx;
+DROP INDEX IF EXISTS people_age_idx;
+DROP INDEX IF EXISTS people_surname_trgm_idx;
+DROP INDEX IF EXISTS people_name_prefix_idx;
@@ -0,0 +1,7 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX people_name_prefix_idx ON people (name text_pattern_ops);
+CREATE INDEX people_surname_trgm_idx ON people USING gin (surname gin_trgm_ops);
+CREATE INDEX people_age_idx ON people (age);
+CREATE INDEX people_gender_idx ON people (gender);
+CREATE INDEX people_nationality_idx ON people (nationality);
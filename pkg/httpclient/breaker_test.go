@@ -0,0 +1,75 @@
+package httpclient
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	tests := []struct {
+		name     string
+		breaker  *breaker
+		failures []bool
+		want     bool
+	}{
+		{
+			name:     "closed below the threshold",
+			breaker:  &breaker{threshold: 3, cooldown: time.Hour},
+			failures: []bool{true, true},
+			want:     true,
+		},
+		{
+			name:     "open at the threshold",
+			breaker:  &breaker{threshold: 3, cooldown: time.Hour},
+			failures: []bool{true, true, true},
+			want:     false,
+		},
+		{
+			name:     "a success resets the failures",
+			breaker:  &breaker{threshold: 3, cooldown: time.Hour},
+			failures: []bool{true, true, false, true, true},
+			want:     true,
+		},
+		{
+			name:     "half-open after the cooldown",
+			breaker:  &breaker{threshold: 1, cooldown: -time.Second},
+			failures: []bool{true},
+			want:     true,
+		},
+		{
+			name:     "disabled",
+			breaker:  &breaker{threshold: 0, cooldown: time.Hour},
+			failures: []bool{true, true, true, true},
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, failed := range tt.failures {
+				tt.breaker.record(failed)
+			}
+
+			if got := tt.breaker.allow(); got != tt.want {
+				t.Errorf("allow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreakerTrial(t *testing.T) {
+	b := &breaker{threshold: 1, cooldown: -time.Second}
+	b.record(true)
+
+	if !b.allow() {
+		t.Fatal("allow() = false for the trial request after the cooldown")
+	}
+	if b.allow() {
+		t.Fatal("allow() = true while the trial request is in flight")
+	}
+
+	b.record(false)
+	if !b.allow() || !b.allow() {
+		t.Fatal("allow() = false after a successful trial request")
+	}
+}
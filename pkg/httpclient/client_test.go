@@ -0,0 +1,179 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	c := New(Backoff(100*time.Millisecond, time.Second))
+
+	tests := []struct {
+		name       string
+		attempt    int
+		retryAfter string
+		max        time.Duration
+	}{
+		{name: "first attempt", attempt: 1, max: 100 * time.Millisecond},
+		{name: "grows exponentially", attempt: 3, max: 400 * time.Millisecond},
+		{name: "capped", attempt: 10, max: time.Second},
+		{name: "shift overflow is capped", attempt: 80, max: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				if got := c.backoff(tt.attempt, nil); got < 0 || got > tt.max {
+					t.Fatalf("backoff(%d) = %v, want between 0 and %v", tt.attempt, got, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestBackoffRetryAfter(t *testing.T) {
+	c := New(Backoff(100*time.Millisecond, time.Second))
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"7"}}}
+
+	if got := c.backoff(1, resp); got != 7*time.Second {
+		t.Errorf("backoff() = %v, want the Retry-After of 7s", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{value: "", wantOK: false},
+		{value: "3", want: 3 * time.Second, wantOK: true},
+		{value: "-1", wantOK: false},
+		{value: "soon", wantOK: false},
+		{value: "Wed, 21 Oct 2015 07:28:00 GMT", want: 0, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantStatus   int
+		wantRequests int32
+	}{
+		{name: "success", statuses: []int{200}, wantStatus: 200, wantRequests: 1},
+		{name: "retries server errors", statuses: []int{503, 502, 200}, wantStatus: 200, wantRequests: 3},
+		{name: "retries rate limiting", statuses: []int{429, 200}, wantStatus: 200, wantRequests: 2},
+		{name: "gives up after max attempts", statuses: []int{500, 500, 500, 200}, wantStatus: 500, wantRequests: 3},
+		{name: "client errors aren't retried", statuses: []int{404, 200}, wantStatus: 404, wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statuses[requests.Add(1)-1])
+			}))
+			defer server.Close()
+
+			c := New(MaxAttempts(3), Backoff(time.Millisecond, time.Millisecond), CircuitBreaker(0, 0))
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+
+			resp, err := c.Do(req)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus || requests.Load() != tt.wantRequests {
+				t.Errorf("Do() = %d after %d requests, want %d after %d",
+					resp.StatusCode, requests.Load(), tt.wantStatus, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestClientCircuitOpen(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	c := New(MaxAttempts(1), CircuitBreaker(2, time.Hour))
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	if _, err := c.Do(req); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Do() error = %v, want ErrCircuitOpen", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("upstream got %d requests, want 2", requests.Load())
+	}
+}
+
+func TestClientCanceledKeepsCircuitClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := New(MaxAttempts(1), CircuitBreaker(1, time.Hour))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if _, err := c.Do(req); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Do() error = %v, want context.Canceled", err)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v, want nil", err)
+	}
+	resp.Body.Close()
+}
+
+func TestClientDeadlineOpensCircuit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := New(MaxAttempts(1), CircuitBreaker(1, time.Hour))
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if _, err := c.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Do() error = %v, want context.DeadlineExceeded", err)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL, nil)
+	if _, err := c.Do(req); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Do() error = %v, want ErrCircuitOpen", err)
+	}
+}
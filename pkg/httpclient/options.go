@@ -0,0 +1,46 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+type Option func(*Client)
+
+func Timeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.client.Timeout = timeout
+	}
+}
+
+func TLSConfig(tlsConfig *tls.Config) Option {
+	return func(c *Client) {
+		if transport, ok := c.client.Transport.(*http.Transport); ok {
+			transport.TLSClientConfig = tlsConfig
+		}
+	}
+}
+
+// MaxAttempts sets how many times a request is sent in total; 1 disables retries.
+func MaxAttempts(attempts int) Option {
+	return func(c *Client) {
+		c.maxAttempts = attempts
+	}
+}
+
+func Backoff(minBackoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.minBackoff = minBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// CircuitBreaker opens the circuit after threshold consecutive failures for
+// the cooldown. A threshold of 0 disables the breaker.
+func CircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *Client) {
+		c.breaker.threshold = threshold
+		c.breaker.cooldown = cooldown
+	}
+}
@@ -0,0 +1,122 @@
+package namecase
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+const _defaultLocale = "en"
+
+// _particles stay lowercase when another word of the name follows them, as in
+// the Dutch "van der Berg" or the Portuguese "da Silva". They are keyed by
+// language, since elsewhere the same words are capitalized, as in the Italian
+// "Di Maio" or the French "Le Pen".
+var _particles = map[string]map[string]bool{
+	"nl": set("van", "der", "den", "de", "het", "te", "ten", "ter"),
+	"de": set("von", "zu", "vom", "zum", "zur", "der"),
+	"pt": set("da", "das", "do", "dos", "de", "e"),
+	"es": set("de", "del", "la", "las", "los", "y"),
+	"fr": set("de", "du", "des"),
+}
+
+// _suffixes stay lowercase when they follow another word of the name, as in
+// the Turkic patronymics "Мамед оглы" or "Алиевна кызы". They are keyed by language.
+var _suffixes = map[string]map[string]bool{
+	"ru": set("оглы", "кызы", "улы", "уулу", "ogly", "kyzy", "uly", "uulu"),
+	"az": set("oğlu", "qızı", "оглы", "кызы"),
+	"kk": set("ұлы", "қызы", "улы", "кызы"),
+	"ky": set("уулу", "кызы"),
+}
+
+func set(words ...string) map[string]bool {
+	m := make(map[string]bool, len(words))
+	for _, word := range words {
+		m[word] = true
+	}
+
+	return m
+}
+
+// Normalizer capitalizes personal names following the conventions of a locale.
+type Normalizer struct {
+	locale language.Tag
+}
+
+func New(opts ...Option) *Normalizer {
+	n := &Normalizer{
+		locale: language.MustParse(_defaultLocale),
+	}
+
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	return n
+}
+
+// Normalize capitalizes name using the rules of locale, a BCP 47 language tag.
+// The default locale is used when locale is empty or invalid.
+func (n *Normalizer) Normalize(name, locale string) string {
+	tag := n.locale
+	if locale != "" {
+		if t, err := language.Parse(locale); err == nil {
+			tag = t
+		}
+	}
+
+	lower := cases.Lower(tag)
+	title := cases.Title(tag)
+	base, _ := tag.Base()
+	particles, suffixes := _particles[base.String()], _suffixes[base.String()]
+
+	words := strings.Fields(name)
+	for i, word := range words {
+		// Every part of a hyphenated name is capitalized on its own.
+		parts := strings.Split(word, "-")
+		for j, part := range parts {
+			parts[j] = normalizeWord(lower.String(part), title)
+		}
+		word = strings.Join(parts, "-")
+
+		lowered := lower.String(word)
+		switch {
+		case particles[lowered] && i < len(words)-1:
+			word = lowered
+		case suffixes[lowered] && i > 0:
+			word = lowered
+		}
+		words[i] = word
+	}
+
+	return strings.Join(words, " ")
+}
+
+// normalizeWord capitalizes a lowercase word, keeping the Mc prefix and the
+// elided O', D' and L' prefixes apart from the rest of the name.
+func normalizeWord(word string, title cases.Caser) string {
+	if prefix, apostrophe, rest, ok := cutApostrophe(word); ok {
+		return title.String(prefix) + apostrophe + normalizeWord(rest, title)
+	}
+
+	if rest, ok := strings.CutPrefix(word, "mc"); ok && rest != "" {
+		return "Mc" + title.String(rest)
+	}
+
+	return title.String(word)
+}
+
+// cutApostrophe splits a word like "o'neil" after a single-letter prefix.
+// Apostrophes inside a word, as in the Ukrainian "мар'яна", are left alone.
+func cutApostrophe(word string) (prefix, apostrophe, rest string, ok bool) {
+	_, size := utf8.DecodeRuneInString(word)
+	for _, apostrophe := range []string{"'", "’"} {
+		if rest, ok := strings.CutPrefix(word[size:], apostrophe); ok && rest != "" {
+			return word[:size], apostrophe, rest, true
+		}
+	}
+
+	return "", "", "", false
+}
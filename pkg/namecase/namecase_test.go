@@ -0,0 +1,56 @@
+package namecase
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		want   string
+	}{
+		{name: "ivan", locale: "en", want: "Ivan"},
+		{name: "  ANNE-MARIE  ", locale: "en", want: "Anne-Marie"},
+		{name: "o'neil", locale: "en", want: "O'Neil"},
+		{name: "mcdonald", locale: "en", want: "McDonald"},
+		{name: "мар'яна", locale: "uk", want: "Мар'яна"},
+		{name: "ijsselmeer", locale: "nl", want: "IJsselmeer"},
+		{name: "ismail", locale: "tr", want: "İsmail"},
+		{name: "van der berg", locale: "nl", want: "van der Berg"},
+		{name: "van der berg", locale: "en", want: "Van Der Berg"},
+		{name: "van", locale: "nl", want: "Van"},
+		{name: "da silva", locale: "pt-BR", want: "da Silva"},
+		{name: "charles de gaulle", locale: "fr", want: "Charles de Gaulle"},
+		{name: "le pen", locale: "fr", want: "Le Pen"},
+		{name: "di maio", locale: "it", want: "Di Maio"},
+		{name: "de luca", locale: "it", want: "De Luca"},
+		{name: "мамед оглы", locale: "ru", want: "Мамед оглы"},
+		{name: "оглы", locale: "ru", want: "Оглы"},
+		{name: "мамед оглы", locale: "en", want: "Мамед Оглы"},
+		{name: "ivan", locale: "not a locale!", want: "Ivan"},
+	}
+
+	n := New()
+	for _, tt := range tests {
+		t.Run(tt.locale+"/"+tt.name, func(t *testing.T) {
+			if got := n.Normalize(tt.name, tt.locale); got != tt.want {
+				t.Errorf("Normalize(%q, %q) = %q, want %q", tt.name, tt.locale, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeIsStable(t *testing.T) {
+	// Names are normalized again when they are changed, which must not alter
+	// a name normalized under the same locale before.
+	n := New()
+	for _, tt := range []struct{ name, locale string }{
+		{"IJsselmeer", "nl"},
+		{"İsmail", "tr"},
+		{"van der Berg", "nl"},
+		{"O'Neil", "en"},
+	} {
+		if got := n.Normalize(tt.name, tt.locale); got != tt.name {
+			t.Errorf("Normalize(%q, %q) = %q, want it unchanged", tt.name, tt.locale, got)
+		}
+	}
+}
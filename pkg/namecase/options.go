@@ -0,0 +1,14 @@
+package namecase
+
+import "golang.org/x/text/language"
+
+type Option func(*Normalizer)
+
+// Locale sets the locale used when a name is normalized without one.
+func Locale(locale string) Option {
+	return func(n *Normalizer) {
+		if tag, err := language.Parse(locale); err == nil {
+			n.locale = tag
+		}
+	}
+}